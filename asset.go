@@ -0,0 +1,238 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const assetHashLen = 8
+
+// Assets generates fingerprinted URLs of assets and serves them, the
+// fingerprints are either read from a manifest file generated by bundlers,
+// such as Vite and webpack, or computed from the content of files. The
+// computed fingerprints are cached, and recomputed once the modification time
+// or size of the file changed.
+type Assets struct {
+	fs       http.FileSystem
+	prefix   string
+	mutex    *sync.RWMutex
+	hashes   map[string]assetHash
+	manifest map[string]string
+	files    map[string]bool
+}
+
+// NewAssets returns an assets with the given file system and URL prefix.
+func NewAssets(fs http.FileSystem, prefix string) *Assets {
+	return &Assets{
+		fs:     fs,
+		prefix: "/" + strings.Trim(prefix, "/"),
+		mutex:  &sync.RWMutex{},
+		hashes: make(map[string]assetHash),
+	}
+}
+
+// assetHash is the computed fingerprint of an asset.
+type assetHash struct {
+	hash    string
+	modTime time.Time
+	size    int64
+}
+
+// LoadManifest loads the manifest file, it supports both Vite's manifest:
+//
+//	{"src/main.js": {"file": "assets/main.4889e940.js"}}
+//
+// and webpack's manifest:
+//
+//	{"main.js": "main.4889e940.js"}
+//
+// The assets listed in the manifest will no longer be fingerprinted by
+// computing hashes.
+func (a *Assets) LoadManifest(filename string) error {
+	content, err := readFile(a.fs, filename)
+	if err != nil {
+		return err
+	}
+	var raw map[string]interface{}
+	if err = json.Unmarshal(content, &raw); err != nil {
+		return fmt.Errorf("failed to load manifest %q: %s", filename, err)
+	}
+
+	manifest := make(map[string]string, len(raw))
+	files := make(map[string]bool, len(raw))
+	for name, value := range raw {
+		var file string
+		switch v := value.(type) {
+		case string:
+			file = v
+		case map[string]interface{}:
+			file, _ = v["file"].(string)
+		}
+		if file == "" {
+			return fmt.Errorf("failed to load manifest %q: invalid entry %q", filename, name)
+		}
+		file = strings.TrimPrefix(file, a.prefix+"/")
+		manifest[cleanAssetPath(name)] = file
+		files[cleanAssetPath(file)] = true
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.manifest = manifest
+	a.files = files
+	return nil
+}
+
+// URL returns the fingerprinted URL of the given asset.
+func (a *Assets) URL(name string) (string, error) {
+	name = cleanAssetPath(name)
+	a.mutex.RLock()
+	file, ok := a.manifest[name]
+	a.mutex.RUnlock()
+	if ok {
+		if strings.HasPrefix(file, "/") || strings.Contains(file, "://") {
+			return file, nil
+		}
+		return path.Join(a.prefix, file), nil
+	}
+
+	hash, err := a.hash(name)
+	if err != nil {
+		return "", err
+	}
+	ext := path.Ext(name)
+	return path.Join(a.prefix, strings.TrimSuffix(name, ext)+"."+hash+ext), nil
+}
+
+// hash returns the fingerprint of the asset, the cached fingerprint is
+// recomputed if the file has been changed since.
+func (a *Assets) hash(name string) (string, error) {
+	file, err := a.fs.Open("/" + name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	a.mutex.RLock()
+	h, ok := a.hashes[name]
+	a.mutex.RUnlock()
+	if ok && h.modTime.Equal(info.ModTime()) && h.size == info.Size() {
+		return h.hash, nil
+	}
+
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	h = assetHash{
+		hash:    hex.EncodeToString(sum[:])[:assetHashLen],
+		modTime: info.ModTime(),
+		size:    info.Size(),
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.hashes[name] = h
+	return h.hash, nil
+}
+
+// ServeHTTP serves the assets, the fingerprinted assets are served with
+// immutable cache headers.
+//
+//	http.Handle("/assets/", assets)
+func (a *Assets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Path
+	if a.prefix != "/" {
+		if !strings.HasPrefix(name, a.prefix+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		name = strings.TrimPrefix(name, a.prefix)
+	}
+	name = cleanAssetPath(name)
+	immutable := false
+	a.mutex.RLock()
+	if a.files[name] {
+		immutable = true
+	}
+	a.mutex.RUnlock()
+	if !immutable {
+		if original, hash, ok := splitAssetHash(name); ok {
+			if actual, err := a.hash(original); err == nil && actual == hash {
+				name = original
+				immutable = true
+			}
+		}
+	}
+
+	file, err := a.fs.Open("/" + name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	if immutable {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
+
+func cleanAssetPath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// splitAssetHash splits the fingerprinted name, such as "css/app.1a2b3c4d.css",
+// into original name and hash.
+func splitAssetHash(name string) (string, string, bool) {
+	ext := path.Ext(name)
+	rest := strings.TrimSuffix(name, ext)
+	if hash, ok := parseAssetHash(path.Ext(rest)); ok {
+		return strings.TrimSuffix(rest, "."+hash) + ext, hash, true
+	}
+	// the asset that has no extension.
+	if hash, ok := parseAssetHash(ext); ok {
+		return rest, hash, true
+	}
+	return "", "", false
+}
+
+func parseAssetHash(ext string) (string, bool) {
+	if len(ext) != assetHashLen+1 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(ext[1:]); err != nil {
+		return "", false
+	}
+	return ext[1:], true
+}
+
+// Asset registers the asset function with the given assets.
+//
+//	<link rel="stylesheet" href="{{ asset "css/app.css" }}">
+func Asset(a *Assets) Option {
+	return func(m *Manager) {
+		m.AddFunc("asset", a.URL)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestAssets(t *testing.T) *Assets {
+	fs := http.Dir(path.Join(string(testDataFileSystem.(http.Dir)), "assets"))
+	a := NewAssets(fs, "/assets/")
+	if err := a.LoadManifest("/manifest.json"); err != nil {
+		t.Fatalf("failed to load manifest: %s", err)
+	}
+	return a
+}
+
+func TestAssetsURL(t *testing.T) {
+	a := newTestAssets(t)
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"css/app.css", "/assets/css/app.eac0e790.css"},
+		{"/css/app.css", "/assets/css/app.eac0e790.css"},
+		{"src/main.js", "/assets/js/main.4889e940.js"},
+		{"cdn.js", "https://cdn.example.com/cdn.js"},
+	}
+	for _, test := range tests {
+		actual, err := a.URL(test.name)
+		if err != nil {
+			t.Fatalf("failed to generate URL: %s", err)
+		}
+		if actual != test.expected {
+			t.Errorf("expected URL %q, got %q", test.expected, actual)
+		}
+	}
+
+	if _, err := a.URL("nonexistent.css"); err == nil {
+		t.Error("expected an error about file not found, got nil")
+	}
+}
+
+func TestAssetsServeHTTP(t *testing.T) {
+	a := newTestAssets(t)
+	tests := []struct {
+		path      string
+		code      int
+		immutable bool
+	}{
+		{"/assets/css/app.eac0e790.css", http.StatusOK, true},
+		{"/assets/js/main.4889e940.js", http.StatusOK, true},
+		{"/assets/css/app.css", http.StatusOK, false},
+		{"/assets/css/app.00000000.css", http.StatusNotFound, false},
+		{"/assets/css", http.StatusNotFound, false},
+		{"/assetsfoo/css/app.css", http.StatusNotFound, false},
+		{"/css/app.css", http.StatusNotFound, false},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if w.Code != test.code {
+			t.Errorf("%s: expected status code %d, got %d", test.path, test.code, w.Code)
+		}
+		cacheControl := w.Header().Get("Cache-Control")
+		if (cacheControl != "") != test.immutable {
+			t.Errorf("%s: unexpected Cache-Control %q", test.path, cacheControl)
+		}
+	}
+}
+
+func TestAssetsHashChanged(t *testing.T) {
+	dir, err := ioutil.TempDir("", "views")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "app.css")
+	if err = ioutil.WriteFile(filename, []byte("body {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAssets(http.Dir(dir), "/assets")
+	before, err := a.URL("app.css")
+	if err != nil {
+		t.Fatalf("failed to generate URL: %s", err)
+	}
+	if err = ioutil.WriteFile(filename, []byte("main {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(time.Hour)
+	if err = os.Chtimes(filename, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	after, err := a.URL("app.css")
+	if err != nil {
+		t.Fatalf("failed to generate URL: %s", err)
+	}
+	if after == before {
+		t.Errorf("expected the fingerprint is recomputed, got %q", after)
+	}
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{after, http.StatusOK},
+		{before, http.StatusNotFound},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if w.Code != test.code {
+			t.Errorf("%s: expected status code %d, got %d", test.path, test.code, w.Code)
+		}
+	}
+}
+
+func TestSplitAssetHash(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		hash     string
+		ok       bool
+	}{
+		{"css/app.eac0e790.css", "css/app.css", "eac0e790", true},
+		{"LICENSE.eac0e790", "LICENSE", "eac0e790", true},
+		{"css/app.css", "", "", false},
+		{"css/app.zzzzzzzz.css", "", "", false},
+	}
+	for _, test := range tests {
+		original, hash, ok := splitAssetHash(test.name)
+		if original != test.original || hash != test.hash || ok != test.ok {
+			t.Errorf("%s: unexpected result %q %q %t", test.name, original, hash, ok)
+		}
+	}
+}
+
+func TestAsset(t *testing.T) {
+	m := New(testViewsFileSystem, Asset(newTestAssets(t)))
+	w := bytes.NewBuffer(nil)
+	if err := m.RenderPartial(w, "asset", nil); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	expected := `<link rel="stylesheet" href="/assets/css/app.eac0e790.css">`
+	if actual := string(bytes.TrimSpace(w.Bytes())); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
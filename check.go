@@ -0,0 +1,178 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// Issue is a problem found by checking.
+type Issue struct {
+	// File is the view name, or the filename of partial.
+	File    string
+	Message string
+}
+
+func (i Issue) String() string {
+	return i.File + ": " + i.Message
+}
+
+// Check compiles every view against its layouts, and reports parse errors,
+// references to undefined templates and unused partials. The issues of view
+// are only reported if it fails with all the registered layouts. The references are
+// only checked for the built-in engines.
+func (m *Manager) Check() ([]Issue, error) {
+	views, err := m.views()
+	if err != nil {
+		return nil, err
+	}
+
+	issues := []Issue{}
+	for _, view := range views {
+		v, err := m.checkView(view)
+		if err != nil {
+			issues = append(issues, Issue{view, err.Error()})
+			continue
+		}
+		for _, name := range undefinedTemplates(v.Template) {
+			issues = append(issues, Issue{view, fmt.Sprintf("template %q is not defined", name)})
+		}
+	}
+
+	used := map[string]bool{}
+	for _, l := range m.layouts {
+		for _, partial := range l.partials {
+			used[m.findPartialFile(partial)] = true
+		}
+	}
+	partialsDir := m.absFilepath(path.Join(m.layoutsDir, m.partialsDir))
+	err = walkFiles(m.fs, partialsDir, func(filename string) error {
+		if strings.HasSuffix(filename, m.suffix) && !used[filename] {
+			issues = append(issues, Issue{filename, "partial is not used by any layout"})
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return issues, nil
+}
+
+// checkView compiles the view, the view that defines no templates is treated
+// as a standalone view that rendered without layout. The view is compiled
+// against its default layout, and then the other registered layouts, since it
+// may be rendered with any of them. The first template that compiled without
+// undefined templates is returned, the result of the default layout is
+// returned if none of them does.
+func (m *Manager) checkView(view string) (*viewTemplate, error) {
+	v, err := m.newTemplate([]string{m.resolveViewFile(view)})
+	if err != nil || len(templateTrees(v.Template)) == 1 {
+		return v, err
+	}
+	var (
+		first    *viewTemplate
+		firstErr error
+	)
+	for i, layout := range m.checkLayouts(view) {
+		var t *viewTemplate
+		files, err := m.templateFiles(layout, view)
+		if err == nil {
+			t, err = m.newTemplate(files)
+		}
+		if err == nil && len(undefinedTemplates(t.Template)) == 0 {
+			return t, nil
+		}
+		if i == 0 {
+			first, firstErr = t, err
+		}
+	}
+	return first, firstErr
+}
+
+// checkLayouts returns the default layout of the view, followed by the other
+// registered layouts in order of name.
+func (m *Manager) checkLayouts(view string) []string {
+	layout := m.viewLayout(view)
+	names := make([]string, 0, len(m.layouts))
+	for name := range m.layouts {
+		if name != layout {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{layout}, names...)
+}
+
+func templateTrees(tmpl Template) map[string]*parse.Tree {
+	trees := map[string]*parse.Tree{}
+	switch t := tmpl.(type) {
+	case htmlTemplate:
+		for _, v := range t.Templates() {
+			if v.Tree != nil {
+				trees[v.Name()] = v.Tree
+			}
+		}
+	case textTemplate:
+		for _, v := range t.Templates() {
+			if v.Tree != nil {
+				trees[v.Name()] = v.Tree
+			}
+		}
+	}
+	return trees
+}
+
+// undefinedTemplates returns the sorted names of templates that are invoked
+// but not defined.
+func undefinedTemplates(tmpl Template) []string {
+	trees := templateTrees(tmpl)
+	undefined := map[string]bool{}
+	for _, tree := range trees {
+		walkNodes(tree.Root, func(node parse.Node) {
+			if n, ok := node.(*parse.TemplateNode); ok {
+				if _, ok := trees[n.Name]; !ok {
+					undefined[n.Name] = true
+				}
+			}
+		})
+	}
+	names := make([]string, 0, len(undefined))
+	for name := range undefined {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func walkNodes(node parse.Node, fn func(parse.Node)) {
+	if node == nil {
+		return
+	}
+	fn(node)
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkNodes(child, fn)
+		}
+	case *parse.IfNode:
+		walkNodes(n.List, fn)
+		walkNodes(n.ElseList, fn)
+	case *parse.RangeNode:
+		walkNodes(n.List, fn)
+		walkNodes(n.ElseList, fn)
+	case *parse.WithNode:
+		walkNodes(n.List, fn)
+		walkNodes(n.ElseList, fn)
+	}
+}
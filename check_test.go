@@ -0,0 +1,55 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestManagerCheck(t *testing.T) {
+	m := New(http.Dir(filepath.Join("testdata", "check")))
+	m.AddFunc("title", strings.Title)
+	m.AddLayout("main", "head")
+	issues, err := m.Check()
+	if err != nil {
+		t.Fatalf("failed to check: %s", err)
+	}
+	// the page view requires the page layout.
+	if len(issues) != 4 || issues[1].String() != `page: template "content" is not defined` {
+		t.Fatalf("expected the page view is reported, got %v", issues)
+	}
+
+	m.AddLayout("page")
+	issues, err = m.Check()
+	if err != nil {
+		t.Fatalf("failed to check: %s", err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("expected 3 issues, got %v", issues)
+	}
+	expected := []Issue{
+		{"undefined", `template "missing" is not defined`},
+		{"/layouts/partials/unused.tmpl", "partial is not used by any layout"},
+	}
+	if issues[0].File != "invalid" {
+		t.Errorf("expected parse error of invalid view, got %s", issues[0])
+	}
+	if !reflect.DeepEqual(issues[1:], expected) {
+		t.Errorf("expected issues %v, got %v", expected, issues[1:])
+	}
+
+	m.AddLayout("main")
+	issues, err = m.Check()
+	if err != nil {
+		t.Fatalf("failed to check: %s", err)
+	}
+	if actual := issues[1].String(); actual != `ok: template "head" is not defined` {
+		t.Errorf("unexpected issue %q", actual)
+	}
+}
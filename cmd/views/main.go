@@ -0,0 +1,195 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+// Command views checks and exports the views.
+//
+//	views check [flags] <dir>
+//	views export [flags] -out <output> <dir>
+//
+// The check command compiles every view against its layouts, and reports parse
+// errors, references to undefined templates and unused partials, a view is
+// only reported if it fails with all the layouts. It exits with
+// status 1 if there are any issues, and 2 for usage or I/O errors.
+//
+// The layouts and their partials are only registered by the config file, so
+// that the views that require a layout report `no such layout "main"` without
+// the -config flag.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/clevergo/views/v2"
+)
+
+const usage = `usage: views <command> [flags] <dir>
+
+commands:
+  check   reports parse errors, undefined templates and unused partials.
+  export  renders all views into the output directory.
+
+The layouts are only registered by the config file (-config), the views
+that require a layout report 'no such layout' without it.
+
+Run 'views <command> -h' for the flags of command.
+`
+
+// stdout and stderr are the outputs of commands, which are replaced in tests.
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+type flags struct {
+	*flag.FlagSet
+	suffix        string
+	delims        string
+	defaultLayout string
+	layoutsDir    string
+	partialsDir   string
+	funcs         string
+	config        string
+	markdown      bool
+}
+
+func newFlags(name string) *flags {
+	f := &flags{FlagSet: flag.NewFlagSet(name, flag.ContinueOnError)}
+	f.SetOutput(stderr)
+	f.StringVar(&f.suffix, "suffix", ".tmpl", "template suffix")
+	f.StringVar(&f.delims, "delims", "{{,}}", "comma-separated left and right delimiters")
+	f.StringVar(&f.defaultLayout, "default-layout", "main", "default layout")
+	f.StringVar(&f.layoutsDir, "layouts", "layouts", "layouts directory, relative to views directory")
+	f.StringVar(&f.partialsDir, "partials", "partials", "partials directory, relative to layouts directory")
+	f.StringVar(&f.funcs, "funcs", "", "comma-separated names of custom template functions")
+	f.StringVar(&f.config, "config", "", "JSON config file that registers layouts, see views.Config, the flags take precedence")
+	f.BoolVar(&f.markdown, "markdown", false, "enable Markdown views")
+	return f
+}
+
+func (f *flags) manager() (*views.Manager, error) {
+	if f.NArg() != 1 {
+		return nil, fmt.Errorf("views directory is required")
+	}
+	delims := strings.Split(f.delims, ",")
+	if len(delims) != 2 {
+		return nil, fmt.Errorf("invalid delimiters %q", f.delims)
+	}
+	opts := []views.Option{}
+	if f.config != "" {
+		dir, filename := filepath.Split(f.config)
+		config, err := views.LoadConfig(http.Dir(dir), filename)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, config.Options()...)
+	}
+	// the flags that are not set explicitly are ignored if config is provided.
+	set := map[string]bool{}
+	f.Visit(func(fl *flag.Flag) {
+		set[fl.Name] = true
+	})
+	flagOpts := map[string]views.Option{
+		"suffix":         views.Suffix(f.suffix),
+		"delims":         views.Delims(delims[0], delims[1]),
+		"default-layout": views.DefaultLayout(f.defaultLayout),
+		"layouts":        views.LayoutsDir(f.layoutsDir),
+		"partials":       views.PartialsDir(f.partialsDir),
+	}
+	for name, opt := range flagOpts {
+		if f.config == "" || set[name] {
+			opts = append(opts, opt)
+		}
+	}
+	if f.markdown {
+		opts = append(opts, views.Markdown(nil))
+	}
+	m := views.New(http.Dir(f.Arg(0)), opts...)
+
+	// the functions are unknown, stubs are used for parsing only.
+	for _, name := range strings.Split(f.funcs, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			m.AddFunc(name, func(...interface{}) interface{} { return nil })
+		}
+	}
+
+	return m, nil
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	switch args[0] {
+	case "check":
+		return check(args[1:])
+	case "export":
+		return export(args[1:])
+	}
+	fmt.Fprint(stderr, usage)
+	return 2
+}
+
+func check(args []string) int {
+	f := newFlags("check")
+	if err := f.Parse(args); err != nil {
+		return 2
+	}
+	m, err := f.manager()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	issues, err := m.Check()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	for _, issue := range issues {
+		fmt.Fprintln(stdout, issue)
+	}
+	if len(issues) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func export(args []string) int {
+	f := newFlags("export")
+	out := f.String("out", "public", "output directory")
+	if err := f.Parse(args); err != nil {
+		return 2
+	}
+	m, err := f.manager()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	pages, err := m.Pages()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	report, err := m.Export(*out, pages)
+	for _, file := range report.Files {
+		fmt.Fprintln(stdout, file)
+	}
+	for _, failure := range report.Failures {
+		fmt.Fprintf(stderr, "%s: %s\n", failure.Page.View, failure.Err)
+	}
+	if err != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	out, err := ioutil.TempDir("", "views")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(out)
+
+	checkDir := filepath.Join("..", "..", "testdata", "check")
+	checkConfig := filepath.Join(checkDir, "views.json")
+	okDir := filepath.Join("testdata", "ok")
+	okConfig := filepath.Join(okDir, "views.json")
+	tests := []struct {
+		args     []string
+		code     int
+		contains string
+		excludes string
+	}{
+		{nil, 2, "usage", ""},
+		{[]string{"unknown"}, 2, "usage", ""},
+		{[]string{"check"}, 2, "views directory is required", ""},
+		{[]string{"check", "-unknown", okDir}, 2, "flag provided but not defined", ""},
+		{[]string{"check", "-delims", "{{", okDir}, 2, "invalid delimiters", ""},
+		{[]string{"check", "-config", "nonexistent.json", okDir}, 2, "", ""},
+		{[]string{"check", "-funcs", "title", "-config", okConfig, okDir}, 0, "", ""},
+		{[]string{"check", "-config", okConfig, okDir}, 1, `function "title" not defined`, ""},
+		{[]string{"check", "-funcs", "title", checkDir}, 1, `ok: no such layout "main"`, ""},
+		{[]string{"check", "-funcs", "title", "-config", checkConfig, checkDir}, 1, "unused.tmpl: partial is not used by any layout", "no such layout"},
+		{[]string{"export", "-funcs", "title", "-config", okConfig, "-out", out, okDir}, 0, "index.html", ""},
+		{[]string{"export", "-funcs", "title", "-config", checkConfig, "-out", out, checkDir}, 1, "missing value for if", ""},
+		{[]string{"export", "-out"}, 2, "flag needs an argument", ""},
+		{[]string{"export", "-out", out}, 2, "views directory is required", ""},
+	}
+	for _, test := range tests {
+		buf := bytes.NewBuffer(nil)
+		stdout, stderr = buf, buf
+		code := run(test.args)
+		output := buf.String()
+		if code != test.code {
+			t.Errorf("%q: expected exit code %d, got %d: %s", test.args, test.code, code, output)
+		}
+		if !strings.Contains(output, test.contains) {
+			t.Errorf("%q: expected output contains %q, got %q", test.args, test.contains, output)
+		}
+		if test.excludes != "" && strings.Contains(output, test.excludes) {
+			t.Errorf("%q: expected output excludes %q, got %q", test.args, test.excludes, output)
+		}
+	}
+	stdout, stderr = os.Stdout, os.Stderr
+
+	content, err := ioutil.ReadFile(filepath.Join(out, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "<html></html>\n"; string(content) != expected {
+		t.Errorf("expected %q, got %q", expected, content)
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Prop is the declaration of a component prop.
+type Prop struct {
+	// Type is one of "string", "number", "bool", "list" and "map", any type
+	// is allowed if empty.
+	Type     string        `json:"type"`
+	Required bool          `json:"required"`
+	Default  interface{}   `json:"default"`
+	Enum     []interface{} `json:"enum"`
+}
+
+// Slots is the rendered blocks that passed to component.
+type Slots map[string]template.HTML
+
+// ComponentData is the data of component template.
+type ComponentData struct {
+	Props map[string]interface{}
+	// Slots contains the default slot under the "default" key, and the named
+	// slots.
+	Slots Slots
+}
+
+// ComponentsDir is an option that registers all files under the directory as
+// components, the component name is the relative path without suffix, such as
+// "button" for "components/button.tmpl".
+//
+//	{{ define "card.body" }}<p>{{ .user.Name }}</p>{{ end }}
+//	{{ define "card.footer" }}<a href="/users">Back</a>{{ end }}
+//	{{ component "button" (props "label" "Save" "variant" "primary") }}
+//	{{ component "card" (props "title" "Profile") (slot "card.body" .) (slots "footer" (slot "card.footer" .)) }}
+//
+// The component template is executed with ComponentData:
+//
+//	<div class="card">
+//		<h2>{{ .Props.title }}</h2>
+//		{{ .Slots.default }}
+//		{{ with .Slots.footer }}<footer>{{ . }}</footer>{{ end }}
+//	</div>
+//
+// The props are validated against the schema which is read from the sidecar
+// file, such as "components/button.json", the unknown props are rejected:
+//
+//	{
+//		"label": {"type": "string", "required": true},
+//		"variant": {"type": "string", "default": "primary", "enum": ["primary", "secondary"]}
+//	}
+//
+// The component files should not define templates.
+func ComponentsDir(dir string) Option {
+	return func(m *Manager) {
+		m.componentsDir = dir
+		m.AddFunc("props", props)
+		m.AddFunc("slots", slots)
+		m.addContextFunc("slot", func(ctx context.Context) interface{} {
+			return func(name string, data interface{}) (template.HTML, error) {
+				return renderSlot(ctx, name, data)
+			}
+		})
+		m.addContextFunc("component", func(ctx context.Context) interface{} {
+			return func(name string, args ...interface{}) (template.HTML, error) {
+				return renderComponent(ctx, name, args)
+			}
+		})
+	}
+}
+
+func componentTemplateName(name string) string {
+	return "component/" + name
+}
+
+// componentSources returns the sources of components, the source is wrapped
+// in a definition named by the component.
+func (m *Manager) componentSources(v *viewTemplate) ([]Source, error) {
+	sources := []Source{}
+	dir := m.absFilepath(m.componentsDir)
+	v.components = map[string]map[string]Prop{}
+	left, right := m.delims[0], m.delims[1]
+	err := walkFiles(m.fs, dir, func(filename string) error {
+		if !strings.HasSuffix(filename, m.suffix) {
+			return nil
+		}
+		content, err := m.readSource(v, filename)
+		if err != nil {
+			return err
+		}
+		content = m.minifySource(content)
+		name := strings.TrimSuffix(strings.TrimPrefix(filename, dir+"/"), m.suffix)
+		if v.components[name], err = m.readPropsSchema(strings.TrimSuffix(filename, m.suffix)); err != nil {
+			return err
+		}
+		buf := bytes.NewBufferString(left + " define " + fmt.Sprintf("%q", componentTemplateName(name)) + " " + right)
+		buf.Write(content)
+		buf.WriteString(left + " end " + right)
+		sources = append(sources, Source{Filename: filename, Content: buf.Bytes()})
+		return nil
+	})
+	return sources, err
+}
+
+func (m *Manager) readPropsSchema(name string) (map[string]Prop, error) {
+	data, err := m.readSidecar(name)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	// converts to JSON, so that the schema is decoded in the same way.
+	content, err := json.Marshal(normalizeValue(data))
+	if err != nil {
+		return nil, err
+	}
+	schema := map[string]Prop{}
+	if err = json.Unmarshal(content, &schema); err != nil {
+		return nil, fmt.Errorf("invalid props schema %q: %s", name, err)
+	}
+	for prop, p := range schema {
+		switch p.Type {
+		case "", "string", "number", "bool", "list", "map":
+		default:
+			return nil, fmt.Errorf("invalid props schema %q: unknown type %q of prop %q", name, p.Type, prop)
+		}
+	}
+	return schema, nil
+}
+
+func props(pairs ...interface{}) (map[string]interface{}, error) {
+	return pairsToMap("props", pairs)
+}
+
+// pairsToMap converts the key-value pairs to a map, fn is the name of function
+// for error messages.
+func pairsToMap(fn string, pairs []interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("%s: requires key-value pairs", fn)
+	}
+	values := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: key must be a string, got %T", fn, pairs[i])
+		}
+		values[key] = pairs[i+1]
+	}
+	return values, nil
+}
+
+func slots(pairs ...interface{}) (Slots, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("slots: requires name-block pairs")
+	}
+	values := make(Slots, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		name, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("slots: name must be a string, got %T", pairs[i])
+		}
+		block, ok := pairs[i+1].(template.HTML)
+		if !ok {
+			return nil, fmt.Errorf("slots: slot %q must be rendered by slot function, got %T", name, pairs[i+1])
+		}
+		values[name] = block
+	}
+	return values, nil
+}
+
+func renderSlot(ctx context.Context, name string, data interface{}) (template.HTML, error) {
+	state := renderStateFromContext(ctx)
+	if state == nil || state.tmpl == nil {
+		return "", errors.New("slot: no template is being rendered")
+	}
+	buf := bytes.NewBuffer(nil)
+	if err := state.tmpl.ExecuteTemplate(buf, name, data); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+func renderComponent(ctx context.Context, name string, args []interface{}) (template.HTML, error) {
+	state := renderStateFromContext(ctx)
+	if state == nil || state.tmpl == nil {
+		return "", errors.New("component: no template is being rendered")
+	}
+	schema, ok := state.components[name]
+	if !ok {
+		return "", fmt.Errorf("component: no such component %q", name)
+	}
+
+	data := ComponentData{Props: map[string]interface{}{}, Slots: Slots{}}
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case nil:
+		case map[string]interface{}:
+			for key, value := range v {
+				data.Props[key] = value
+			}
+		case Slots:
+			for key, value := range v {
+				data.Slots[key] = value
+			}
+		case template.HTML:
+			data.Slots["default"] = v
+		default:
+			return "", fmt.Errorf("component %q: unexpected argument of type %T", name, arg)
+		}
+	}
+	if schema != nil {
+		if err := validateProps(schema, data.Props); err != nil {
+			return "", fmt.Errorf("component %q: %s", name, err)
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := state.tmpl.ExecuteTemplate(buf, componentTemplateName(name), data); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// validateProps validates the props against the schema, and fills the
+// default values.
+func validateProps(schema map[string]Prop, props map[string]interface{}) error {
+	unknown := []string{}
+	for key := range props {
+		if _, ok := schema[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown props %q", unknown)
+	}
+
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop := schema[name]
+		value, ok := props[name]
+		if !ok || value == nil {
+			if prop.Required {
+				return fmt.Errorf("prop %q is required", name)
+			}
+			if prop.Default != nil {
+				props[name] = prop.Default
+			}
+			continue
+		}
+		if !matchPropType(prop.Type, value) {
+			return fmt.Errorf("prop %q must be a %s, got %T", name, prop.Type, value)
+		}
+		if len(prop.Enum) > 0 && !containsValue(prop.Enum, value) {
+			return fmt.Errorf("prop %q must be one of %v, got %v", name, prop.Enum, value)
+		}
+	}
+	return nil
+}
+
+func matchPropType(typ string, value interface{}) bool {
+	kind := reflect.ValueOf(value).Kind()
+	switch typ {
+	case "string":
+		return kind == reflect.String
+	case "number":
+		return kind >= reflect.Int && kind <= reflect.Float64
+	case "bool":
+		return kind == reflect.Bool
+	case "list":
+		return kind == reflect.Slice || kind == reflect.Array
+	case "map":
+		return kind == reflect.Map
+	}
+	return true
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	s := fmt.Sprint(value)
+	for _, v := range values {
+		if fmt.Sprint(v) == s {
+			return true
+		}
+	}
+	return false
+}
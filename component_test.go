@@ -0,0 +1,151 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"net/http"
+	"path"
+	"reflect"
+	"testing"
+)
+
+var testComponentsFileSystem = http.Dir(path.Join("testdata", "components"))
+
+func TestComponent(t *testing.T) {
+	m := New(testComponentsFileSystem, ComponentsDir("components"))
+	w := bytes.NewBuffer(nil)
+	if err := m.RenderPartial(w, "profile", map[string]interface{}{"name": "<foo>"}); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	expected := `<div class="card"><h2>Profile</h2><p>&lt;foo&gt;</p><footer><button class="btn btn-primary">Back</button>
+</footer></div>
+
+<input name="email">
+`
+	if actual := w.String(); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestComponentProps(t *testing.T) {
+	m := New(testComponentsFileSystem, ComponentsDir("components"))
+	tests := []struct {
+		props    map[string]interface{}
+		expected string
+		err      string
+	}{
+		{map[string]interface{}{"label": "Save"}, "<button class=\"btn btn-primary\">Save</button>\n\n", ""},
+		{map[string]interface{}{"label": "Save", "variant": "secondary", "disabled": true}, "<button class=\"btn btn-secondary\">Save</button>\n\n", ""},
+		{map[string]interface{}{}, "", `prop "label" is required`},
+		{map[string]interface{}{"label": 1}, "", `prop "label" must be a string, got int`},
+		{map[string]interface{}{"label": "Save", "variant": "danger"}, "", `prop "variant" must be one of [primary secondary], got danger`},
+		{map[string]interface{}{"label": "Save", "size": "lg", "color": "red"}, "", `unknown props ["color" "size"]`},
+	}
+	for _, test := range tests {
+		w := bytes.NewBuffer(nil)
+		err := m.RenderPartial(w, "invalid", map[string]interface{}{"props": test.props})
+		if test.err != "" {
+			if err == nil || !bytes.Contains([]byte(err.Error()), []byte(`component "button": `+test.err)) {
+				t.Errorf("expected error %q, got %v", test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		if actual := w.String(); actual != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+}
+
+func TestComponentViews(t *testing.T) {
+	m := New(testComponentsFileSystem, ComponentsDir("components"))
+	views, err := m.views()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"invalid", "profile"}; !reflect.DeepEqual(views, expected) {
+		t.Errorf("expected views %q, got %q", expected, views)
+	}
+}
+
+func TestComponentErrors(t *testing.T) {
+	ctx := context.WithValue(context.Background(), renderStateKey{}, &renderState{})
+	if _, err := renderComponent(context.Background(), "button", nil); err == nil {
+		t.Error("expected an error, got nil")
+	}
+	if _, err := renderSlot(ctx, "body", nil); err == nil {
+		t.Error("expected an error, got nil")
+	}
+
+	m := New(testComponentsFileSystem, ComponentsDir("components"))
+	v, err := m.getTemplate("", "profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx = context.WithValue(context.Background(), renderStateKey{}, &renderState{tmpl: v.Template, components: v.components})
+	args := [][]interface{}{
+		{"invalid"},
+		{map[string]interface{}{"name": "foo"}, Slots{"default": template.HTML("foo")}, template.HTML("bar"), nil},
+	}
+	if _, err := renderComponent(ctx, "forms/input", args[0]); err == nil {
+		t.Error("expected an error about unexpected argument, got nil")
+	}
+	if output, err := renderComponent(ctx, "forms/input", args[1]); err != nil || output != `<input name="foo">` {
+		t.Errorf("unexpected output %q, error %v", output, err)
+	}
+	if _, err := renderComponent(ctx, "unknown", nil); err == nil {
+		t.Error("expected an error about unknown component, got nil")
+	}
+
+	m = New(testComponentsFileSystem, ComponentsDir("nonexistent"))
+	if _, err := m.getTemplate("", "profile"); err == nil {
+		t.Error("expected an error about nonexistent directory, got nil")
+	}
+}
+
+func TestPropsAndSlots(t *testing.T) {
+	if _, err := props("foo"); err == nil {
+		t.Error("expected an error, got nil")
+	}
+	if _, err := props(1, "foo"); err == nil {
+		t.Error("expected an error, got nil")
+	}
+	if _, err := slots("foo"); err == nil {
+		t.Error("expected an error, got nil")
+	}
+	if _, err := slots(1, template.HTML("foo")); err == nil {
+		t.Error("expected an error, got nil")
+	}
+	if _, err := slots("foo", "bar"); err == nil {
+		t.Error("expected an error, got nil")
+	}
+}
+
+func TestMatchPropType(t *testing.T) {
+	tests := []struct {
+		typ      string
+		value    interface{}
+		expected bool
+	}{
+		{"", struct{}{}, true},
+		{"number", 1, true},
+		{"number", 1.5, true},
+		{"number", "1", false},
+		{"list", []string{}, true},
+		{"list", "foo", false},
+		{"map", map[string]int{}, true},
+		{"map", []int{}, false},
+	}
+	for _, test := range tests {
+		if actual := matchPropType(test.typ, test.value); actual != test.expected {
+			t.Errorf("matchPropType(%q, %#v): expected %t, got %t", test.typ, test.value, test.expected, actual)
+		}
+	}
+}
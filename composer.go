@@ -0,0 +1,103 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"context"
+	"fmt"
+	"path"
+)
+
+// Composer returns the data that will be merged into the data of the view
+// that it attached to.
+type Composer func(ctx context.Context, view string) (map[string]interface{}, error)
+
+type composer struct {
+	pattern string
+	f       Composer
+}
+
+// Compose attaches a composer to the views, layouts and partials that match
+// the pattern, the pattern syntax is the same as path.Match. Layouts and
+// partials are matched by their paths, such as "layouts/main" and
+// "layouts/partials/header".
+//
+//	m.Compose("layouts/main", func(ctx context.Context, view string) (map[string]interface{}, error) {
+//		return map[string]interface{}{"user": currentUser(ctx)}, nil
+//	})
+//	m.Compose("user/*", ...)
+//
+// Composers are invoked in order of layout, partials and view before
+// rendering, the data passed by caller takes precedence over the composed
+// data, they require the data to be nil or a map[string]interface{}.
+func (m *Manager) Compose(pattern string, f Composer) {
+	m.composers = append(m.composers, &composer{pattern, f})
+}
+
+func (m *Manager) composeTargets(layout, view string) []string {
+	targets := []string{}
+	if layout != "" {
+		targets = append(targets, path.Join(m.layoutsDir, layout))
+		if l, ok := m.layouts[layout]; ok {
+			for _, partial := range l.partials {
+				targets = append(targets, path.Join(m.layoutsDir, m.partialsDir, partial))
+			}
+		}
+	}
+	return append(targets, view)
+}
+
+func (m *Manager) compose(ctx context.Context, layout, view string, data interface{}) (interface{}, error) {
+	if len(m.composers) == 0 {
+		return data, nil
+	}
+
+	composed := map[string]interface{}{}
+	for _, target := range m.composeTargets(layout, view) {
+		for _, c := range m.composers {
+			matched, err := path.Match(c.pattern, target)
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				continue
+			}
+			values, err := c.f(ctx, view)
+			if err != nil {
+				return nil, err
+			}
+			for key, value := range values {
+				composed[key] = value
+			}
+		}
+	}
+	if len(composed) == 0 {
+		return data, nil
+	}
+
+	return mergeData(data, composed)
+}
+
+// mergeData returns a new map that contains the values and the data, the data
+// takes precedence over the values.
+func mergeData(data interface{}, values map[string]interface{}) (map[string]interface{}, error) {
+	var dataMap map[string]interface{}
+	switch v := data.(type) {
+	case nil:
+	case map[string]interface{}:
+		dataMap = v
+	default:
+		return nil, fmt.Errorf("unable to merge data into %T, map[string]interface{} expected", data)
+	}
+
+	merged := make(map[string]interface{}, len(values)+len(dataMap))
+	for key, value := range values {
+		merged[key] = value
+	}
+	for key, value := range dataMap {
+		merged[key] = value
+	}
+	return merged, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestManagerCompose(t *testing.T) {
+	m := New(testViewsFileSystem)
+	m.AddLayout("main", "nav")
+	m.Compose("layouts/main", func(ctx context.Context, view string) (map[string]interface{}, error) {
+		return map[string]interface{}{"user": "foo", "title": "layout"}, nil
+	})
+	m.Compose("layouts/partials/nav", func(ctx context.Context, view string) (map[string]interface{}, error) {
+		return map[string]interface{}{"menu": "bar"}, nil
+	})
+	m.Compose("cont*", func(ctx context.Context, view string) (map[string]interface{}, error) {
+		return map[string]interface{}{"title": view}, nil
+	})
+
+	tests := []struct {
+		data     interface{}
+		expected string
+	}{
+		{nil, "<main>foo bar content</main>"},
+		{map[string]interface{}{"title": "home"}, "<main>foo bar home</main>"},
+	}
+	for _, test := range tests {
+		w := bytes.NewBuffer(nil)
+		if err := m.Render(w, "content", test.data); err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		if actual := string(bytes.TrimSpace(w.Bytes())); actual != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+
+	if err := m.Render(bytes.NewBuffer(nil), "content", struct{}{}); err == nil {
+		t.Error("expected an error about unmergeable data, got nil")
+	}
+
+	errCompose := errors.New("compose")
+	m.Compose("content", func(ctx context.Context, view string) (map[string]interface{}, error) {
+		return nil, errCompose
+	})
+	if err := m.Render(bytes.NewBuffer(nil), "content", nil); err != errCompose {
+		t.Errorf("expected error %s, got %v", errCompose, err)
+	}
+}
+
+func TestMergeData(t *testing.T) {
+	values := map[string]interface{}{"foo": "bar", "fizz": "buzz"}
+	tests := []struct {
+		data     interface{}
+		expected map[string]interface{}
+	}{
+		{nil, map[string]interface{}{"foo": "bar", "fizz": "buzz"}},
+		{map[string]interface{}{"foo": "baz"}, map[string]interface{}{"foo": "baz", "fizz": "buzz"}},
+	}
+	for _, test := range tests {
+		actual, err := mergeData(test.data, values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("expected %v, got %v", test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Encoder compresses the data and writes to w.
+type Encoder func(w io.Writer, data []byte) error
+
+type encoding struct {
+	name    string
+	encoder Encoder
+}
+
+// Compression is an option that compresses the output of HTTP renders that
+// are at least minSize bytes, the content encoding is negotiated with the
+// Accept-Encoding header. gzip is supported out of box, the other encodings,
+// such as brotli, can be registered by ContentEncoding.
+func Compression(minSize int) Option {
+	return func(m *Manager) {
+		m.compress = true
+		m.compressMinSize = minSize
+	}
+}
+
+// ContentEncoding is an option that registers an encoder for the given
+// content encoding, it takes precedence over gzip and the encoders that
+// registered later if the client accepts them equally.
+//
+//	views.ContentEncoding("br", func(w io.Writer, data []byte) error {
+//		bw := brotli.NewWriter(w)
+//		if _, err := bw.Write(data); err != nil {
+//			return err
+//		}
+//		return bw.Close()
+//	})
+func ContentEncoding(name string, encoder Encoder) Option {
+	return func(m *Manager) {
+		name = strings.ToLower(name)
+		for i, e := range m.encodings {
+			if e.name == name {
+				m.encodings[i].encoder = encoder
+				return
+			}
+		}
+		m.encodings = append(m.encodings, encoding{name, encoder})
+	}
+}
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(nil)
+	},
+}
+
+func encodeGzip(w io.Writer, data []byte) error {
+	gw := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(gw)
+	gw.Reset(w)
+	if _, err := gw.Write(data); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// negotiateEncoding returns the preferred encoding that the client accepts,
+// returns false if none of them are acceptable.
+func (m *Manager) negotiateEncoding(header string) (encoding, bool) {
+	encodings := append(m.encodings[:len(m.encodings):len(m.encodings)], encoding{"gzip", encodeGzip})
+	accepted := parseAcceptEncoding(header)
+	var (
+		best  encoding
+		bestQ float64
+	)
+	for _, e := range encodings {
+		q, ok := accepted[e.name]
+		if !ok {
+			q = accepted["*"]
+		}
+		if q > bestQ {
+			best, bestQ = e, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// parseAcceptEncoding parses the Accept-Encoding header, and returns the
+// quality values of encodings.
+func parseAcceptEncoding(header string) map[string]float64 {
+	accepted := map[string]float64{}
+	for _, v := range strings.Split(header, ",") {
+		parts := strings.Split(v, ";")
+		name := strings.ToLower(strings.TrimSpace(parts[0]))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				var err error
+				if q, err = strconv.ParseFloat(param[2:], 64); err != nil {
+					q = 0
+				}
+			}
+		}
+		accepted[name] = q
+	}
+	return accepted
+}
+
+// addVary adds the value to the Vary header if absent.
+func addVary(header http.Header, value string) {
+	for _, v := range header["Vary"] {
+		for _, field := range strings.Split(v, ",") {
+			if field = strings.TrimSpace(field); field == "*" || strings.EqualFold(field, value) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", value)
+}
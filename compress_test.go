@@ -0,0 +1,152 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestCompression(t *testing.T) {
+	reverse := func(w io.Writer, data []byte) error {
+		for i := len(data) - 1; i >= 0; i-- {
+			if _, err := w.Write(data[i : i+1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	m := New(testViewsFileSystem, Compression(11), ContentEncoding("BR", nil), ContentEncoding("br", reverse))
+	tests := []struct {
+		acceptEncoding string
+		title          string
+		encoding       string
+		body           string
+	}{
+		{"", "foo", "", "<h1>foo</h1>\n"},
+		{"gzip", "", "", "<h1></h1>\n"},
+		{"gzip", "foo", "gzip", "<h1>foo</h1>\n"},
+		{"gzip, deflate, br", "foo", "br", "\n>1h/<oof>1h<"},
+		{"gzip;q=1.0, br;q=0.5", "foo", "gzip", "<h1>foo</h1>\n"},
+		{"*", "foo", "br", "\n>1h/<oof>1h<"},
+		{"*, br;q=0", "foo", "gzip", "<h1>foo</h1>\n"},
+		{"gzip;q=0, br;q=invalid", "foo", "", "<h1>foo</h1>\n"},
+		{"deflate", "foo", "", "<h1>foo</h1>\n"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", test.acceptEncoding)
+		resp := httptest.NewRecorder()
+		if err := m.RenderPartialHTTP(resp, req, "page", map[string]interface{}{"title": test.title}); err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		if vary := resp.Header().Get("Vary"); vary != "Accept-Encoding" {
+			t.Errorf("%q: unexpected Vary header %q", test.acceptEncoding, vary)
+		}
+		if encoding := resp.Header().Get("Content-Encoding"); encoding != test.encoding {
+			t.Errorf("%q: expected encoding %q, got %q", test.acceptEncoding, test.encoding, encoding)
+		}
+		if contentLength := resp.Header().Get("Content-Length"); contentLength != strconv.Itoa(resp.Body.Len()) {
+			t.Errorf("%q: unexpected Content-Length %q", test.acceptEncoding, contentLength)
+		}
+		body := resp.Body.Bytes()
+		if test.encoding == "gzip" {
+			r, err := gzip.NewReader(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if body, err = ioutil.ReadAll(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if string(body) != test.body {
+			t.Errorf("%q: expected body %q, got %q", test.acceptEncoding, test.body, body)
+		}
+	}
+}
+
+func TestCompressionETag(t *testing.T) {
+	m := New(testViewsFileSystem, Compression(0))
+	data := map[string]interface{}{"title": "foo"}
+	etags := map[string]string{}
+	for _, encoding := range []string{"gzip", "identity"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", encoding)
+		resp := httptest.NewRecorder()
+		if err := m.RenderPartialHTTP(resp, req, "page", data); err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		etags[encoding] = resp.Header().Get("ETag")
+	}
+	if etags["gzip"] == etags["identity"] {
+		t.Errorf("expected different ETags, got %q", etags["gzip"])
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("If-None-Match", etags["gzip"])
+	resp := httptest.NewRecorder()
+	if err := m.RenderPartialHTTP(resp, req, "page", data); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	if resp.Code != http.StatusNotModified {
+		t.Errorf("expected status code %d, got %d", http.StatusNotModified, resp.Code)
+	}
+}
+
+func TestCompressionEncoderError(t *testing.T) {
+	m := New(testViewsFileSystem, Compression(0), ContentEncoding("br", func(w io.Writer, data []byte) error {
+		return errors.New("encoder error")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "br")
+	resp := httptest.NewRecorder()
+	if err := m.RenderPartialHTTP(resp, req, "page", nil); err == nil || err.Error() != "encoder error" {
+		t.Errorf("expected encoder error, got %v", err)
+	}
+}
+
+func TestCompressionEncoded(t *testing.T) {
+	m := New(testViewsFileSystem, Compression(0))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp := httptest.NewRecorder()
+	resp.Header().Set("Content-Encoding", "identity")
+	if err := m.RenderPartialHTTP(resp, req, "page", nil); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	if !bytes.Equal(resp.Body.Bytes(), []byte("<h1></h1>\n")) {
+		t.Errorf("expected the output is not compressed, got %q", resp.Body.Bytes())
+	}
+}
+
+func TestAddVary(t *testing.T) {
+	tests := []struct {
+		vary     []string
+		expected []string
+	}{
+		{nil, []string{"Accept-Encoding"}},
+		{[]string{"Cookie"}, []string{"Cookie", "Accept-Encoding"}},
+		{[]string{"Cookie, accept-encoding"}, []string{"Cookie, accept-encoding"}},
+		{[]string{"*"}, []string{"*"}},
+	}
+	for _, test := range tests {
+		header := http.Header{}
+		for _, v := range test.vary {
+			header.Add("Vary", v)
+		}
+		addVary(header, "Accept-Encoding")
+		if actual := header["Vary"]; len(actual) != len(test.expected) || actual[len(actual)-1] != test.expected[len(test.expected)-1] {
+			t.Errorf("expected Vary %q, got %q", test.expected, actual)
+		}
+	}
+}
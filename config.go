@@ -0,0 +1,177 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Config is the configuration of manager, for example:
+//
+//	{
+//		"suffix": ".tmpl",
+//		"delims": ["{{", "}}"],
+//		"defaultLayout": "main",
+//		"layoutsDir": "layouts",
+//		"partialsDir": "partials",
+//		"layouts": {
+//			"main": ["head", "header", "footer"],
+//			"page": ["head"]
+//		},
+//		"cache": true
+//	}
+//
+// The empty settings are ignored.
+type Config struct {
+	Suffix        string              `json:"suffix"`
+	Delims        []string            `json:"delims"`
+	DefaultLayout string              `json:"defaultLayout"`
+	LayoutsDir    string              `json:"layoutsDir"`
+	PartialsDir   string              `json:"partialsDir"`
+	Layouts       map[string][]string `json:"layouts"`
+	Cache         *bool               `json:"cache"`
+}
+
+// LoadConfig loads the configuration file from the filesystem, only JSON is
+// supported out of box, other formats, such as YAML and TOML, require to be
+// registered by RegisterFormat. Unknown keys are reported as errors.
+func LoadConfig(fs http.FileSystem, filename string) (*Config, error) {
+	content, err := readFile(fs, filename)
+	if err != nil {
+		return nil, err
+	}
+	config, err := parseConfig(filename, content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %q: %s", filename, err)
+	}
+	return config, nil
+}
+
+func parseConfig(filename string, content []byte) (*Config, error) {
+	raw := map[string]interface{}{}
+	if err := unmarshalFile(filename, content, &raw); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]bool{}
+	typ := reflect.TypeOf(Config{})
+	for i := 0; i < typ.NumField(); i++ {
+		keys[strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]] = true
+	}
+	unknown := []string{}
+	for key := range raw {
+		if !keys[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown keys %q", unknown)
+	}
+
+	// converts to JSON, so that the values are validated in the same way.
+	data, err := json.Marshal(normalizeValue(raw))
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	config := &Config{}
+	if err = decoder.Decode(config); err != nil {
+		return nil, err
+	}
+	if err = config.validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// normalizeValue converts the map[interface{}]interface{} that produced by
+// some YAML decoders to map[string]interface{}.
+func normalizeValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for key, val := range value {
+			m[fmt.Sprint(key)] = normalizeValue(val)
+		}
+		return m
+	case map[string]interface{}:
+		for key, val := range value {
+			value[key] = normalizeValue(val)
+		}
+	case []interface{}:
+		for i, val := range value {
+			value[i] = normalizeValue(val)
+		}
+	}
+	return v
+}
+
+func (c *Config) validate() error {
+	if c.Delims != nil && (len(c.Delims) != 2 || c.Delims[0] == "" || c.Delims[1] == "") {
+		return fmt.Errorf("delims requires non-empty left and right delimiters, got %q", c.Delims)
+	}
+	for name := range c.Layouts {
+		if name == "" {
+			return fmt.Errorf("layout name is required")
+		}
+	}
+	return nil
+}
+
+// Options returns the options of configuration.
+func (c *Config) Options() []Option {
+	opts := []Option{}
+	if c.Suffix != "" {
+		opts = append(opts, Suffix(c.Suffix))
+	}
+	if c.Delims != nil {
+		opts = append(opts, Delims(c.Delims[0], c.Delims[1]))
+	}
+	if c.DefaultLayout != "" {
+		opts = append(opts, DefaultLayout(c.DefaultLayout))
+	}
+	if c.LayoutsDir != "" {
+		opts = append(opts, LayoutsDir(c.LayoutsDir))
+	}
+	if c.PartialsDir != "" {
+		opts = append(opts, PartialsDir(c.PartialsDir))
+	}
+	if c.Cache != nil {
+		opts = append(opts, Cache(*c.Cache))
+	}
+	for name, partials := range c.Layouts {
+		opts = append(opts, layoutOption(name, partials))
+	}
+	return opts
+}
+
+func layoutOption(name string, partials []string) Option {
+	return func(m *Manager) {
+		m.AddLayout(name, partials...)
+	}
+}
+
+// NewFromConfig returns a manager with the configuration file that loaded from
+// the filesystem, the given options are applied after the configuration. Only
+// JSON is supported out of box, YAML and TOML configuration files require to
+// register the unmarshal functions by RegisterFormat first:
+//
+//	views.RegisterFormat(".yaml", yaml.Unmarshal)
+//	manager, err := views.NewFromConfig(fs, "views.yaml")
+func NewFromConfig(fs http.FileSystem, configFile string, opts ...Option) (*Manager, error) {
+	config, err := LoadConfig(fs, configFile)
+	if err != nil {
+		return nil, err
+	}
+	return New(fs, append(config.Options(), opts...)...), nil
+}
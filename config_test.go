@@ -0,0 +1,81 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	m, err := NewFromConfig(testDataFileSystem, "/config/views.json", Suffix(".html"))
+	if err != nil {
+		t.Fatalf("failed to create manager: %s", err)
+	}
+	if m.cache || m.defaultLayout != "main" || m.suffix != ".html" {
+		t.Errorf("unexpected settings: cache %t, default layout %q, suffix %q", m.cache, m.defaultLayout, m.suffix)
+	}
+	expected := map[string]*layout{
+		"main": {"main", []string{"head", "header", "footer"}},
+		"page": {"page", []string{"head"}},
+	}
+	if !reflect.DeepEqual(m.layouts, expected) {
+		t.Errorf("expected layouts %v, got %v", expected, m.layouts)
+	}
+
+	if _, err = NewFromConfig(testDataFileSystem, "/config/nonexistent.json"); err == nil {
+		t.Error("expected an error about file not found, got nil")
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		content string
+		valid   bool
+	}{
+		{`{}`, true},
+		{`{"suffix": ".html", "layouts": {"main": []}}`, true},
+		{`{"sufix": ".html"}`, false},
+		{`{"suffix": 1}`, false},
+		{`{"delims": ["{{"]}`, false},
+		{`{"delims": ["", "}}"]}`, false},
+		{`{"layouts": {"": ["head"]}}`, false},
+		{`{"layouts": {"main": "head"}}`, false},
+		{`invalid`, false},
+	}
+	for _, test := range tests {
+		_, err := parseConfig("views.json", []byte(test.content))
+		if (err == nil) != test.valid {
+			t.Errorf("%s: unexpected error %v", test.content, err)
+		}
+	}
+}
+
+func TestParseConfigNormalize(t *testing.T) {
+	RegisterFormat(".fakeyaml", func(data []byte, v interface{}) error {
+		*(v.(*map[string]interface{})) = map[string]interface{}{
+			"layouts": map[interface{}]interface{}{
+				"main": []interface{}{"head"},
+			},
+		}
+		return nil
+	})
+	config, err := parseConfig("views.fakeyaml", nil)
+	if err != nil {
+		t.Fatalf("failed to parse config: %s", err)
+	}
+	expected := map[string][]string{"main": {"head"}}
+	if !reflect.DeepEqual(config.Layouts, expected) {
+		t.Errorf("expected layouts %v, got %v", expected, config.Layouts)
+	}
+}
+
+func TestNewFromConfigUnregisteredFormat(t *testing.T) {
+	_, err := NewFromConfig(testDataFileSystem, "config/views.yaml")
+	if err == nil || !strings.Contains(err.Error(), "RegisterFormat") {
+		t.Errorf("expected an error about registering format, got %v", err)
+	}
+}
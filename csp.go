@@ -0,0 +1,58 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+)
+
+// DefaultCSPPolicy is the default Content Security Policy.
+const DefaultCSPPolicy = "default-src 'self'; script-src 'self' 'nonce-{nonce}'; style-src 'self' 'nonce-{nonce}'; object-src 'none'; base-uri 'self'"
+
+// ContentSecurityPolicy is an option that generates a cryptographic nonce per
+// render, which can be accessed by the cspNonce function:
+//
+//	<script nonce="{{ cspNonce }}">...</script>
+//
+// The HTTP renders set the Content-Security-Policy header with the given
+// policy, the "{nonce}" placeholders are replaced with the nonce, defaults to
+// DefaultCSPPolicy. Note that the pages that use nonce always have different
+// ETags, and the nonce should not be used within fragment caches.
+func ContentSecurityPolicy(policy string) Option {
+	if policy == "" {
+		policy = DefaultCSPPolicy
+	}
+	return func(m *Manager) {
+		m.cspPolicy = policy
+		m.addContextFunc("cspNonce", func(ctx context.Context) interface{} {
+			return func() string {
+				nonce, _ := ctx.Value(nonceKey{}).(string)
+				return nonce
+			}
+		})
+	}
+}
+
+type nonceKey struct{}
+
+// withNonce returns a context with the nonce if absent.
+func withNonce(ctx context.Context) (context.Context, string, error) {
+	if nonce, ok := ctx.Value(nonceKey{}).(string); ok {
+		return ctx, nonce, nil
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ctx, "", err
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(b)
+	return context.WithValue(ctx, nonceKey{}, nonce), nonce, nil
+}
+
+func (m *Manager) cspHeader(nonce string) string {
+	return strings.Replace(m.cspPolicy, "{nonce}", nonce, -1)
+}
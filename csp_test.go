@@ -0,0 +1,75 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+var cspNonceRegexp = regexp.MustCompile(`^<script nonce="([A-Za-z0-9_-]{22})"></script><style nonce="([A-Za-z0-9_-]{22})"></style>\n$`)
+
+func TestContentSecurityPolicy(t *testing.T) {
+	m := New(testViewsFileSystem, ContentSecurityPolicy(""))
+	nonces := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		w := bytes.NewBuffer(nil)
+		if err := m.RenderPartial(w, "csp", nil); err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		matches := cspNonceRegexp.FindStringSubmatch(w.String())
+		if matches == nil {
+			t.Fatalf("unexpected output %q", w.String())
+		}
+		if matches[1] != matches[2] {
+			t.Errorf("expected the same nonce within a render, got %q and %q", matches[1], matches[2])
+		}
+		nonces[matches[1]] = true
+	}
+	if len(nonces) != 2 {
+		t.Error("expected different nonces per render")
+	}
+}
+
+func TestContentSecurityPolicyHTTP(t *testing.T) {
+	tests := []struct {
+		policy   string
+		expected string
+	}{
+		{"", DefaultCSPPolicy},
+		{"script-src 'nonce-{nonce}' 'strict-dynamic'", "script-src 'nonce-{nonce}' 'strict-dynamic'"},
+	}
+	for _, test := range tests {
+		m := New(testViewsFileSystem, ContentSecurityPolicy(test.policy))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		resp := httptest.NewRecorder()
+		if err := m.RenderPartialHTTP(resp, req, "csp", nil); err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		matches := cspNonceRegexp.FindStringSubmatch(resp.Body.String())
+		if matches == nil {
+			t.Fatalf("unexpected output %q", resp.Body.String())
+		}
+		m.cspPolicy = test.expected
+		if header := resp.Header().Get("Content-Security-Policy"); header != m.cspHeader(matches[1]) {
+			t.Errorf("unexpected Content-Security-Policy header %q", header)
+		}
+	}
+}
+
+func TestContentSecurityPolicyDisabled(t *testing.T) {
+	m := New(testViewsFileSystem)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	resp := httptest.NewRecorder()
+	if err := m.RenderPartialHTTP(resp, req, "page", nil); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	if header := resp.Header().Get("Content-Security-Policy"); header != "" {
+		t.Errorf("expected no Content-Security-Policy header, got %q", header)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+)
+
+// Email contains the HTML and plain text bodies of an email.
+type Email struct {
+	HTML []byte
+	Text []byte
+}
+
+// WriteMultipart writes the bodies as a multipart/alternative message into w,
+// and returns the Content-Type header value that contains the boundary.
+func (e *Email) WriteMultipart(w io.Writer) (string, error) {
+	mw := multipart.NewWriter(w)
+	parts := []struct {
+		contentType string
+		body        []byte
+	}{
+		{"text/plain; charset=UTF-8", e.Text},
+		{"text/html; charset=UTF-8", e.HTML},
+	}
+	for _, part := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type": {part.contentType},
+		})
+		if err != nil {
+			return "", err
+		}
+		if _, err = pw.Write(part.body); err != nil {
+			return "", err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+	return "multipart/alternative; boundary=" + mw.Boundary(), nil
+}
+
+// Mailer renders the HTML and plain text bodies of emails from sibling files,
+// such as "emails/welcome.html.tmpl" and "emails/welcome.txt.tmpl".
+type Mailer struct {
+	HTML *Manager
+	Text *Manager
+}
+
+// NewMailer returns a mailer with the given filesystem and options, the HTML
+// and plain text managers share the same options, and their suffixes are
+// prefixed by ".html" and ".txt" respectively.
+func NewMailer(fs http.FileSystem, opts ...Option) *Mailer {
+	return &Mailer{
+		HTML: New(fs, append(opts, suffixPrefix(".html"))...),
+		Text: NewText(fs, append(opts, suffixPrefix(".txt"))...),
+	}
+}
+
+func suffixPrefix(prefix string) Option {
+	return func(m *Manager) {
+		m.suffix = prefix + m.suffix
+	}
+}
+
+// AddLayout adds a layout to both HTML and plain text managers.
+func (m *Mailer) AddLayout(name string, partials ...string) {
+	m.HTML.AddLayout(name, partials...)
+	m.Text.AddLayout(name, partials...)
+}
+
+// AddFunc adds function to the funcMap of both HTML and plain text managers.
+func (m *Mailer) AddFunc(name string, f interface{}) {
+	m.HTML.AddFunc(name, f)
+	m.Text.AddFunc(name, f)
+}
+
+// Render renders an email with default layout.
+func (m *Mailer) Render(ctx context.Context, view string, data interface{}) (*Email, error) {
+	return m.render(ctx, m.HTML.defaultLayout, m.Text.defaultLayout, view, data)
+}
+
+// RenderLayout renders an email with particular layout.
+func (m *Mailer) RenderLayout(ctx context.Context, layout, view string, data interface{}) (*Email, error) {
+	return m.render(ctx, layout, layout, view, data)
+}
+
+// RenderPartial renders an email without layout.
+func (m *Mailer) RenderPartial(ctx context.Context, view string, data interface{}) (*Email, error) {
+	return m.render(ctx, "", "", view, data)
+}
+
+func (m *Mailer) render(ctx context.Context, htmlLayout, textLayout, view string, data interface{}) (*Email, error) {
+	html := bytes.NewBuffer(nil)
+	if err := m.HTML.render(ctx, html, htmlLayout, view, data); err != nil {
+		return nil, err
+	}
+	text := bytes.NewBuffer(nil)
+	if err := m.Text.render(ctx, text, textLayout, view, data); err != nil {
+		return nil, err
+	}
+	return &Email{HTML: html.Bytes(), Text: text.Bytes()}, nil
+}
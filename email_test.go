@@ -0,0 +1,71 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+)
+
+func TestMailer(t *testing.T) {
+	m := NewMailer(testViewsFileSystem, DefaultLayout("mail"), Share(map[string]interface{}{"appName": "foo"}))
+	m.AddLayout("mail")
+	m.AddFunc("upper", strings.ToUpper)
+	if m.HTML.suffix != ".html.tmpl" || m.Text.suffix != ".txt.tmpl" {
+		t.Fatalf("unexpected suffixes %q and %q", m.HTML.suffix, m.Text.suffix)
+	}
+	if _, ok := m.Text.funcMap["upper"]; !ok {
+		t.Error("failed to add function")
+	}
+
+	email, err := m.Render(context.Background(), "emails/welcome", map[string]interface{}{"name": "<bar>"})
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	expectedHTML := "<body><h1>Welcome &lt;bar&gt;</h1></body>"
+	if actual := strings.TrimSpace(string(email.HTML)); actual != expectedHTML {
+		t.Errorf("expected HTML %q, got %q", expectedHTML, actual)
+	}
+	expectedText := "Welcome <bar>\n--\nfoo"
+	if actual := strings.TrimSpace(string(email.Text)); actual != expectedText {
+		t.Errorf("expected text %q, got %q", expectedText, actual)
+	}
+
+	if _, err = m.RenderLayout(context.Background(), "nonexistent", "emails/welcome", nil); err == nil {
+		t.Error("expected an error about layout not found, got nil")
+	}
+	if _, err = m.RenderPartial(context.Background(), "emails/nonexistent", nil); err == nil {
+		t.Error("expected an error about view file not found, got nil")
+	}
+}
+
+func TestEmailWriteMultipart(t *testing.T) {
+	email := &Email{HTML: []byte("<h1>foo</h1>"), Text: []byte("foo")}
+	w := bytes.NewBuffer(nil)
+	contentType, err := email.WriteMultipart(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+	r := multipart.NewReader(w, params["boundary"])
+	for _, expected := range []string{"foo", "<h1>foo</h1>"} {
+		part, err := r.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(part)
+		if string(body) != expected {
+			t.Errorf("expected part %q, got %q", expected, body)
+		}
+	}
+}
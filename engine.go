@@ -0,0 +1,95 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"path"
+	texttemplate "text/template"
+)
+
+// Source is the content of a template file.
+type Source struct {
+	Filename string
+	Content  []byte
+}
+
+// Engine is the template engine that parses template files.
+type Engine interface {
+	// Parse parses the sources of a view into a template, the sources are
+	// ordered by layout, partials and view, the first source is the entry
+	// template.
+	Parse(sources []Source, funcs map[string]interface{}) (Template, error)
+}
+
+// Template is a parsed template of a view, its layout and partials.
+type Template interface {
+	// Execute applies the entry template to the data and writes the output to w.
+	Execute(w io.Writer, data interface{}) error
+
+	// ExecuteTemplate applies the template or block of the given name.
+	ExecuteTemplate(w io.Writer, name string, data interface{}) error
+
+	// Bind returns a copy of the template whose functions are replaced by the
+	// given functions, it is called if there are functions that bound to the
+	// render context, the template itself must not be changed. The copies are
+	// pooled and reused by the following renders, one render at a time.
+	Bind(funcs map[string]interface{}) (Template, error)
+}
+
+// htmlEngine is the default engine that bases on html/template.
+type htmlEngine struct {
+	delims []string
+}
+
+func (e htmlEngine) Parse(sources []Source, funcs map[string]interface{}) (Template, error) {
+	tmpl := htmltemplate.New(path.Base(sources[0].Filename)).Funcs(funcs).Delims(e.delims[0], e.delims[1])
+	for _, source := range sources {
+		if _, err := tmpl.Parse(string(source.Content)); err != nil {
+			return nil, err
+		}
+	}
+	return htmlTemplate{tmpl}, nil
+}
+
+type htmlTemplate struct {
+	*htmltemplate.Template
+}
+
+func (t htmlTemplate) Bind(funcs map[string]interface{}) (Template, error) {
+	tmpl, err := t.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return htmlTemplate{tmpl.Funcs(funcs)}, nil
+}
+
+// textEngine is the engine that bases on text/template.
+type textEngine struct {
+	delims []string
+}
+
+func (e textEngine) Parse(sources []Source, funcs map[string]interface{}) (Template, error) {
+	tmpl := texttemplate.New(path.Base(sources[0].Filename)).Funcs(funcs).Delims(e.delims[0], e.delims[1])
+	for _, source := range sources {
+		if _, err := tmpl.Parse(string(source.Content)); err != nil {
+			return nil, err
+		}
+	}
+	return textTemplate{tmpl}, nil
+}
+
+type textTemplate struct {
+	*texttemplate.Template
+}
+
+func (t textTemplate) Bind(funcs map[string]interface{}) (Template, error) {
+	tmpl, err := t.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return textTemplate{tmpl.Funcs(funcs)}, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// concatEngine is a fake engine that outputs the filenames of sources.
+type concatEngine struct {
+	parsed int
+}
+
+func (e *concatEngine) Parse(sources []Source, funcs map[string]interface{}) (Template, error) {
+	e.parsed++
+	tmpl := &concatTemplate{blocks: map[string]string{}}
+	for _, source := range sources {
+		tmpl.blocks[source.Filename] = source.Filename
+		tmpl.output += source.Filename + ";"
+	}
+	return tmpl, nil
+}
+
+type concatTemplate struct {
+	output string
+	blocks map[string]string
+}
+
+func (t *concatTemplate) Execute(w io.Writer, data interface{}) error {
+	_, err := io.WriteString(w, t.output)
+	return err
+}
+
+func (t *concatTemplate) ExecuteTemplate(w io.Writer, name string, data interface{}) error {
+	block, ok := t.blocks[name]
+	if !ok {
+		return fmt.Errorf("no such block %q", name)
+	}
+	_, err := io.WriteString(w, block)
+	return err
+}
+
+func (t *concatTemplate) Bind(funcs map[string]interface{}) (Template, error) {
+	return t, nil
+}
+
+func TestTemplateEngine(t *testing.T) {
+	engine := &concatEngine{}
+	m := New(testFileSystem, TemplateEngine(engine))
+	m.AddLayout("main", "head")
+	for i := 0; i < 2; i++ {
+		w := bytes.NewBuffer(nil)
+		if err := m.Render(w, "site/index", nil); err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		expected := "/layouts/main.tmpl;/layouts/partials/head.tmpl;/site/index.tmpl;"
+		if w.String() != expected {
+			t.Errorf("expected %q, got %q", expected, w.String())
+		}
+	}
+	if engine.parsed != 1 {
+		t.Errorf("expected the template to be cached, parsed %d times", engine.parsed)
+	}
+}
+
+func TestHTMLEngine(t *testing.T) {
+	engine := htmlEngine{[]string{"[[", "]]"}}
+	tmpl, err := engine.Parse([]Source{
+		{"main", []byte(`[[ template "content" . ]]`)},
+		{"view", []byte(`[[ define "content" ]][[ foo ]] [[ . ]][[ end ]]`)},
+	}, map[string]interface{}{"foo": func() string { return "foo" }})
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	bound, err := tmpl.Bind(map[string]interface{}{"foo": func() string { return "bar" }})
+	if err != nil {
+		t.Fatalf("failed to bind: %s", err)
+	}
+	tests := []struct {
+		tmpl     Template
+		expected string
+	}{
+		{bound, "bar &lt;p&gt;"},
+		{tmpl, "foo &lt;p&gt;"},
+	}
+	for _, test := range tests {
+		w := bytes.NewBuffer(nil)
+		if err = test.tmpl.ExecuteTemplate(w, "content", "<p>"); err != nil {
+			t.Fatalf("failed to execute: %s", err)
+		}
+		if w.String() != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, w.String())
+		}
+	}
+
+	if _, err = engine.Parse([]Source{{"invalid", []byte("[[ end ]]")}}, nil); err == nil {
+		t.Error("expected a parse error, got nil")
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Page is a page to be exported.
+type Page struct {
+	// Path is the URL path of page, such as "/", "/about" and "/404.html",
+	// the page is written to "about/index.html" if the path has no ".html"
+	// extension. Defaults to the view name.
+	Path string
+
+	// Layout is the layout of page, defaults to the default layout of view.
+	Layout string
+
+	// Partial indicates whether to render the view without layout.
+	Partial bool
+
+	View string
+	Data interface{}
+}
+
+func (p Page) filename() string {
+	name := p.Path
+	if name == "" {
+		name = p.View
+	}
+	name = path.Clean("/" + name)
+	if strings.HasSuffix(name, ".html") {
+		return name
+	}
+	if base := path.Base(name); base == "index" {
+		name = path.Dir(name)
+	}
+	return path.Join(name, "index.html")
+}
+
+// ExportFailure is a page that failed to be exported.
+type ExportFailure struct {
+	Page Page
+	Err  error
+}
+
+// ExportReport is the report of exporting.
+type ExportReport struct {
+	// Files is the list of written files, relative to the output directory.
+	Files    []string
+	Failures []ExportFailure
+}
+
+// Export renders the pages into the output directory as HTML files, it keeps
+// exporting other pages if a page failed, and returns an error if there are
+// any failures.
+func (m *Manager) Export(dir string, pages []Page) (*ExportReport, error) {
+	report := &ExportReport{}
+	buf := bytes.NewBuffer(nil)
+	for _, page := range pages {
+		buf.Reset()
+		if err := m.exportPage(dir, page, buf); err != nil {
+			report.Failures = append(report.Failures, ExportFailure{page, err})
+			continue
+		}
+		report.Files = append(report.Files, page.filename()[1:])
+	}
+
+	if len(report.Failures) > 0 {
+		failure := report.Failures[0]
+		return report, fmt.Errorf("%d of %d pages failed to export, %q: %s", len(report.Failures), len(pages), failure.Page.View, failure.Err)
+	}
+	return report, nil
+}
+
+func (m *Manager) exportPage(dir string, page Page, buf *bytes.Buffer) error {
+	layout := page.Layout
+	if page.Partial {
+		layout = ""
+	} else if layout == "" {
+		layout = m.viewLayout(page.View)
+	}
+	if err := m.render(context.Background(), buf, layout, page.View, page.Data); err != nil {
+		return err
+	}
+
+	filename := filepath.Join(dir, filepath.FromSlash(page.filename()))
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, buf.Bytes(), 0644)
+}
+
+// Pages discovers all views except layouts and partials, the data of page
+// is read from the sidecar file that has the same name as the view, such as
+// "about.json" for "about.tmpl", see RegisterFormat for other formats.
+func (m *Manager) Pages() ([]Page, error) {
+	views, err := m.views()
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]Page, 0, len(views))
+	for _, view := range views {
+		page := Page{View: view}
+		data, err := m.readSidecar(m.absFilepath(view))
+		if err != nil {
+			return nil, err
+		}
+		if data != nil {
+			page.Data = data
+		}
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
+
+// views returns the sorted names of all views except layouts and partials.
+func (m *Manager) views() ([]string, error) {
+	views := []string{}
+	layoutsDir := m.absFilepath(m.layoutsDir)
+	componentsDir := ""
+	if m.componentsDir != "" {
+		componentsDir = m.absFilepath(m.componentsDir)
+	}
+	err := walkFiles(m.fs, "/", func(filename string) error {
+		if strings.HasPrefix(filename, layoutsDir+"/") || componentsDir != "" && strings.HasPrefix(filename, componentsDir+"/") {
+			return nil
+		}
+		switch {
+		case strings.HasSuffix(filename, m.suffix):
+			views = append(views, strings.TrimSuffix(filename, m.suffix)[1:])
+		case m.markdown != nil && strings.HasSuffix(filename, markdownSuffix):
+			views = append(views, strings.TrimSuffix(filename, markdownSuffix)[1:])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the view may have both template and Markdown files.
+	sort.Strings(views)
+	unique := views[:0]
+	for i, view := range views {
+		if i == 0 || view != views[i-1] {
+			unique = append(unique, view)
+		}
+	}
+	return unique, nil
+}
+
+func (m *Manager) readSidecar(view string) (map[string]interface{}, error) {
+	formatsMutex.RLock()
+	exts := make([]string, 0, len(formats))
+	for ext := range formats {
+		exts = append(exts, ext)
+	}
+	formatsMutex.RUnlock()
+	sort.Strings(exts)
+
+	for _, ext := range exts {
+		content, err := readFile(m.fs, view+ext)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		data := map[string]interface{}{}
+		if err = unmarshalFile(ext, content, &data); err != nil {
+			return nil, fmt.Errorf("failed to parse %q: %s", view+ext, err)
+		}
+		return data, nil
+	}
+	return nil, nil
+}
+
+// walkFiles walks the file tree rooted at dir, calling fn for each file.
+func walkFiles(fs http.FileSystem, dir string, fn func(filename string) error) error {
+	d, err := fs.Open(dir)
+	if err != nil {
+		return err
+	}
+	infos, err := d.Readdir(-1)
+	d.Close()
+	if err != nil {
+		return err
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name() < infos[j].Name()
+	})
+	for _, info := range infos {
+		filename := path.Join(dir, info.Name())
+		if info.IsDir() {
+			err = walkFiles(fs, filename, fn)
+		} else {
+			err = fn(filename)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
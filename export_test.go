@@ -0,0 +1,108 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestExportManager() *Manager {
+	m := New(http.Dir(filepath.Join("testdata", "export")), Markdown(nil))
+	m.AddLayout("main")
+	return m
+}
+
+func TestManagerPages(t *testing.T) {
+	pages, err := newTestExportManager().Pages()
+	if err != nil {
+		t.Fatalf("failed to discover pages: %s", err)
+	}
+	expected := []Page{
+		{View: "about", Data: map[string]interface{}{"name": "foo"}},
+		{View: "blog/post"},
+		{View: "broken"},
+		{View: "index"},
+	}
+	if !reflect.DeepEqual(pages, expected) {
+		t.Errorf("expected pages %v, got %v", expected, pages)
+	}
+}
+
+func TestManagerExport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "views")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := newTestExportManager()
+	pages, err := m.Pages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages = append(pages,
+		Page{Path: "/404.html", View: "index", Partial: true},
+		Page{Path: "/about-me", View: "about", Layout: "nonexistent"},
+	)
+	report, err := m.Export(dir, pages)
+	if err == nil {
+		t.Error("expected an error about failures, got nil")
+	}
+	if len(report.Failures) != 2 || report.Failures[0].Page.View != "broken" || report.Failures[1].Page.Path != "/about-me" {
+		t.Errorf("unexpected failures %v", report.Failures)
+	}
+
+	files := map[string]string{
+		"about/index.html":     "<html>about foo</html>",
+		"blog/post/index.html": "<html><h1>Post</h1>\n</html>",
+		"index.html":           "<html>home</html>",
+		"404.html":             "",
+	}
+	if len(report.Files) != len(files) {
+		t.Errorf("expected %d files, got %v", len(files), report.Files)
+	}
+	for _, file := range report.Files {
+		expected, ok := files[file]
+		if !ok {
+			t.Errorf("unexpected file %q", file)
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := strings.TrimSpace(string(content)); actual != expected {
+			t.Errorf("%s: expected %q, got %q", file, expected, actual)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(dir, "broken")); !os.IsNotExist(err) {
+		t.Error("expected no output of the failed page")
+	}
+}
+
+func TestPageFilename(t *testing.T) {
+	tests := []struct {
+		page     Page
+		expected string
+	}{
+		{Page{View: "index"}, "/index.html"},
+		{Page{View: "site/index"}, "/site/index.html"},
+		{Page{View: "about"}, "/about/index.html"},
+		{Page{Path: "/", View: "home"}, "/index.html"},
+		{Page{Path: "/docs/", View: "docs/index"}, "/docs/index.html"},
+		{Page{Path: "../404.html", View: "404"}, "/404.html"},
+	}
+	for _, test := range tests {
+		if actual := test.page.filename(); actual != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+}
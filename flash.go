@@ -0,0 +1,136 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+)
+
+// FlashesKey is the reserved data key of flash messages.
+const FlashesKey = "flashes"
+
+// Flash is a one-shot message.
+type Flash struct {
+	// Kind is the kind of message, such as "success" and "error".
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// FlashStore stores the flash messages.
+type FlashStore interface {
+	// Flashes returns the flash messages of the request without consuming them.
+	Flashes(r *http.Request) ([]Flash, error)
+
+	// Consume removes the flash messages of the request.
+	Consume(w http.ResponseWriter, r *http.Request) error
+}
+
+// FlashMessages is an option that injects the flash messages into data under
+// the FlashesKey key in HTTP renders, the messages are consumed only if the
+// render succeeded. The key is reserved, the value of the same key in data is
+// overridden if there are messages. The data must be nil or a map[string]interface{}, the
+// messages are neither injected nor consumed for other types of data.
+//
+//	{{ range .flashes }}<div class="alert alert-{{ .Kind }}">{{ .Message }}</div>{{ end }}
+func FlashMessages(store FlashStore) Option {
+	return func(m *Manager) {
+		m.flashStore = store
+	}
+}
+
+// CookieFlashStore is a flash store that stores the messages in a cookie,
+// the cookie is neither encrypted nor signed, so that it should not contain
+// sensitive data.
+type CookieFlashStore struct {
+	// Cookie is the template of cookie, such as name, path and domain.
+	Cookie http.Cookie
+}
+
+// NewCookieFlashStore returns a cookie flash store with the given cookie name.
+func NewCookieFlashStore(name string) *CookieFlashStore {
+	return &CookieFlashStore{
+		Cookie: http.Cookie{
+			Name:     name,
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		},
+	}
+}
+
+// Add adds a flash message, which will be shown in the next render, such as
+// after redirecting.
+func (s *CookieFlashStore) Add(w http.ResponseWriter, r *http.Request, flash Flash) error {
+	flashes := s.pending(w)
+	if flashes == nil {
+		flashes, _ = s.Flashes(r)
+	}
+	content, err := json.Marshal(append(flashes, flash))
+	if err != nil {
+		return err
+	}
+	cookie := s.Cookie
+	cookie.Value = base64.RawURLEncoding.EncodeToString(content)
+	s.setCookie(w, &cookie)
+	return nil
+}
+
+// pending returns the flash messages that added within current response.
+func (s *CookieFlashStore) pending(w http.ResponseWriter) []Flash {
+	for _, cookie := range (&http.Response{Header: w.Header()}).Cookies() {
+		if cookie.Name == s.Cookie.Name && cookie.MaxAge >= 0 {
+			return decodeFlashes(cookie.Value)
+		}
+	}
+	return nil
+}
+
+// setCookie replaces the pending cookie of the same name.
+func (s *CookieFlashStore) setCookie(w http.ResponseWriter, cookie *http.Cookie) {
+	header := w.Header()
+	cookies := header["Set-Cookie"]
+	header.Del("Set-Cookie")
+	for _, v := range cookies {
+		c := (&http.Response{Header: http.Header{"Set-Cookie": {v}}}).Cookies()
+		if len(c) == 0 || c[0].Name != cookie.Name {
+			header.Add("Set-Cookie", v)
+		}
+	}
+	http.SetCookie(w, cookie)
+}
+
+// Flashes implements FlashStore, the malformed cookie is ignored.
+func (s *CookieFlashStore) Flashes(r *http.Request) ([]Flash, error) {
+	cookie, err := r.Cookie(s.Cookie.Name)
+	if err != nil {
+		return nil, nil
+	}
+	return decodeFlashes(cookie.Value), nil
+}
+
+// Consume implements FlashStore.
+func (s *CookieFlashStore) Consume(w http.ResponseWriter, r *http.Request) error {
+	if _, err := r.Cookie(s.Cookie.Name); err != nil {
+		return nil
+	}
+	cookie := s.Cookie
+	cookie.MaxAge = -1
+	s.setCookie(w, &cookie)
+	return nil
+}
+
+func decodeFlashes(value string) []Flash {
+	content, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil
+	}
+	flashes := []Flash{}
+	if err = json.Unmarshal(content, &flashes); err != nil {
+		return nil
+	}
+	return flashes
+}
@@ -0,0 +1,142 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestFlashMessages(t *testing.T) {
+	store := NewCookieFlashStore("flash")
+
+	// adds flash messages, and redirects.
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	flashes := []Flash{{"success", "saved"}, {"error", "<failed>"}}
+	for _, flash := range flashes {
+		if err := store.Add(resp, req, flash); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cookies := resp.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected one cookie, got %d", len(cookies))
+	}
+
+	m := New(testViewsFileSystem, FlashMessages(store))
+	m.BeforeRender(func(e *RenderEvent) error {
+		if e.Data.(map[string]interface{})["title"] == "fail" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+	if actual, _ := store.Flashes(req); !reflect.DeepEqual(actual, flashes) {
+		t.Fatalf("expected flashes %v, got %v", flashes, actual)
+	}
+
+	// the flashes are kept if render failed.
+	resp = httptest.NewRecorder()
+	if err := m.RenderPartialHTTP(resp, req, "flash", map[string]interface{}{"title": "fail"}); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if len(resp.Result().Cookies()) != 0 {
+		t.Error("expected the flashes are not consumed")
+	}
+
+	resp = httptest.NewRecorder()
+	if err := m.RenderPartialHTTP(resp, req, "flash", map[string]interface{}{"title": "foo"}); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	expected := `<p class="success">saved</p><p class="error">&lt;failed&gt;</p>foo` + "\n"
+	if actual := resp.Body.String(); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	cookies = resp.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "flash" || cookies[0].MaxAge != -1 {
+		t.Errorf("expected the flashes are consumed, got cookies %v", cookies)
+	}
+
+	// the FlashesKey is reserved.
+	resp = httptest.NewRecorder()
+	data := map[string]interface{}{"title": "foo", FlashesKey: []Flash{{"info", "caller"}}}
+	if err := m.RenderPartialHTTP(resp, req, "flash", data); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	if actual := resp.Body.String(); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	if len(resp.Result().Cookies()) != 1 {
+		t.Error("expected the flashes are consumed")
+	}
+	if actual := data[FlashesKey]; !reflect.DeepEqual(actual, []Flash{{"info", "caller"}}) {
+		t.Errorf("expected the data is not modified, got %v", actual)
+	}
+
+	// no flashes.
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	resp = httptest.NewRecorder()
+	if err := m.RenderPartialHTTP(resp, req, "flash", map[string]interface{}{"title": "foo"}); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	if actual := resp.Body.String(); actual != "foo\n" {
+		t.Errorf("expected %q, got %q", "foo\n", actual)
+	}
+	if len(resp.Result().Cookies()) != 0 {
+		t.Error("expected no cookies")
+	}
+}
+
+func TestFlashMessagesStructData(t *testing.T) {
+	store := NewCookieFlashStore("flash")
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	store.Add(resp, req, Flash{"success", "saved"})
+	req.AddCookie(resp.Result().Cookies()[0])
+
+	m := New(testViewsFileSystem, FlashMessages(store))
+	resp = httptest.NewRecorder()
+	if err := m.RenderPartialHTTP(resp, req, "struct", struct{ Title string }{"foo"}); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	if actual := resp.Body.String(); actual != "<h1>foo</h1>\n" {
+		t.Errorf("unexpected body %q", actual)
+	}
+	if len(resp.Result().Cookies()) != 0 {
+		t.Error("expected the flashes are not consumed")
+	}
+}
+
+func TestCookieFlashStore(t *testing.T) {
+	store := NewCookieFlashStore("flash")
+	for _, value := range []string{"invalid base64!", "bm90IGpzb24"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "flash", Value: value})
+		if flashes, err := store.Flashes(req); err != nil || flashes != nil {
+			t.Errorf("expected malformed cookie is ignored, got %v, %v", flashes, err)
+		}
+	}
+
+	// keeps the other cookies.
+	resp := httptest.NewRecorder()
+	http.SetCookie(resp, &http.Cookie{Name: "session", Value: "foo"})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	store.Add(resp, req, Flash{"success", "saved"})
+	cookies := resp.Result().Cookies()
+	if len(cookies) != 2 || cookies[0].Name != "session" {
+		t.Errorf("unexpected cookies %v", cookies)
+	}
+
+	// consumes nothing if there is no flashes.
+	resp = httptest.NewRecorder()
+	if err := store.Consume(resp, req); err != nil || len(resp.Result().Cookies()) != 0 {
+		t.Errorf("expected no cookies, got %v, %v", resp.Result().Cookies(), err)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// DefaultCSRFField is the default name of CSRF form field.
+const DefaultCSRFField = "csrf_token"
+
+// CSRFTokenFunc returns the CSRF token of the request.
+type CSRFTokenFunc func(r *http.Request) string
+
+// CSRF is an option that registers the csrfToken and csrfField functions,
+// which are only available in HTTP renders, the token is returned by the
+// given function, such as csrf.Token of gorilla/csrf. The field defaults to
+// DefaultCSRFField if empty.
+//
+//	<form method="post">{{ csrfField }}...</form>
+//	<meta name="csrf-token" content="{{ csrfToken }}">
+func CSRF(field string, token CSRFTokenFunc) Option {
+	if field == "" {
+		field = DefaultCSRFField
+	}
+	return func(m *Manager) {
+		m.addContextFunc("csrfToken", func(ctx context.Context) interface{} {
+			return func() (string, error) {
+				return csrfToken(ctx, token)
+			}
+		})
+		m.addContextFunc("csrfField", func(ctx context.Context) interface{} {
+			return func() (template.HTML, error) {
+				value, err := csrfToken(ctx, token)
+				if err != nil {
+					return "", err
+				}
+				return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(field) + `" value="` + template.HTMLEscapeString(value) + `">`), nil
+			}
+		})
+	}
+}
+
+func csrfToken(ctx context.Context, token CSRFTokenFunc) (string, error) {
+	r := requestFromContext(ctx)
+	if r == nil {
+		return "", errors.New("csrf: no HTTP request is being rendered")
+	}
+	return token(r), nil
+}
+
+type requestKey struct{}
+
+func requestFromContext(ctx context.Context) *http.Request {
+	r, _ := ctx.Value(requestKey{}).(*http.Request)
+	return r
+}
+
+// Forms is an option that registers the form helpers, formInput renders an
+// input with the name, value, validation errors and attributes, the boolean
+// attribute is omitted if false. The event handler, style and URL-valued
+// attributes, such as onclick, style and formaction, are rejected, since the
+// values are only HTML-escaped, use html/template to build such inputs.
+// formErrors returns the error messages of
+// the field. The validation errors is a map keyed by field names, the value
+// can be a string, an error, or a slice of them.
+//
+//	{{ formInput "email" .form.email .errors "type" "email" "required" true }}
+//	{{ range formErrors "email" .errors }}<p class="error">{{ . }}</p>{{ end }}
+func Forms() Option {
+	return func(m *Manager) {
+		m.AddFunc("formInput", formInput)
+		m.AddFunc("formErrors", formErrors)
+	}
+}
+
+func formInput(name string, value, errs interface{}, attrs ...interface{}) (template.HTML, error) {
+	if len(attrs)%2 != 0 {
+		return "", errors.New("formInput: requires attribute name-value pairs")
+	}
+	buf := bytes.NewBufferString(`<input name="` + template.HTMLEscapeString(name) + `"`)
+	if value != nil {
+		buf.WriteString(` value="` + template.HTMLEscapeString(fmt.Sprint(value)) + `"`)
+	}
+	for i := 0; i < len(attrs); i += 2 {
+		attr, ok := attrs[i].(string)
+		if !ok || !isAttrName(attr) {
+			return "", fmt.Errorf("formInput: invalid attribute name %v", attrs[i])
+		}
+		if isUnsafeAttr(attr) {
+			return "", fmt.Errorf("formInput: unsafe attribute %q", attr)
+		}
+		if b, ok := attrs[i+1].(bool); ok {
+			if b {
+				buf.WriteString(" " + attr)
+			}
+			continue
+		}
+		buf.WriteString(" " + attr + `="` + template.HTMLEscapeString(fmt.Sprint(attrs[i+1])) + `"`)
+	}
+	if len(formErrors(name, errs)) > 0 {
+		buf.WriteString(` aria-invalid="true"`)
+	}
+	buf.WriteString(">")
+	return template.HTML(buf.String()), nil
+}
+
+func isAttrName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !isWordChar(c) && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// formURLAttrs are the attributes whose values are URLs or documents.
+var formURLAttrs = map[string]bool{
+	"action":     true,
+	"background": true,
+	"cite":       true,
+	"data":       true,
+	"formaction": true,
+	"href":       true,
+	"icon":       true,
+	"longdesc":   true,
+	"manifest":   true,
+	"ping":       true,
+	"poster":     true,
+	"src":        true,
+	"srcdoc":     true,
+	"srcset":     true,
+	"usemap":     true,
+	"xmlns":      true,
+}
+
+// isUnsafeAttr reports whether the attribute can run script, such as the
+// event handlers, style and URL-valued attributes.
+func isUnsafeAttr(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "on") || name == "style" || formURLAttrs[name]
+}
+
+func formErrors(name string, errs interface{}) []string {
+	v := reflect.ValueOf(errs)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return nil
+	}
+	value := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+	if !value.IsValid() {
+		return nil
+	}
+	return errorMessages(value.Interface())
+}
+
+func errorMessages(v interface{}) []string {
+	switch e := v.(type) {
+	case nil:
+		return nil
+	case string:
+		if e == "" {
+			return nil
+		}
+		return []string{e}
+	case error:
+		return []string{e.Error()}
+	case []string:
+		return e
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice {
+		messages := []string{}
+		for i := 0; i < rv.Len(); i++ {
+			messages = append(messages, errorMessages(rv.Index(i).Interface())...)
+		}
+		return messages
+	}
+	return []string{fmt.Sprint(v)}
+}
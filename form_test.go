@@ -0,0 +1,97 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSRFAndForms(t *testing.T) {
+	token := func(r *http.Request) string {
+		return r.Header.Get("X-Token")
+	}
+	m := New(testViewsFileSystem, CSRF("", token), Forms())
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.Header.Set("X-Token", `"foo"`)
+	resp := httptest.NewRecorder()
+	data := map[string]interface{}{
+		"form":   map[string]string{"email": "<foo>"},
+		"errors": map[string][]string{"email": {"invalid email"}},
+	}
+	if err := m.RenderPartialHTTP(resp, req, "user/login", data); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	expected := `<form method="post"><input type="hidden" name="csrf_token" value="&#34;foo&#34;">` +
+		`<input name="email" value="&lt;foo&gt;" type="email" required aria-invalid="true"><p>invalid email</p></form>` +
+		`<meta content="&#34;foo&#34;">` + "\n"
+	if actual := resp.Body.String(); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	// CSRF functions are only available in HTTP renders.
+	if err := m.RenderPartial(bytes.NewBuffer(nil), "user/login", nil); err == nil || !strings.Contains(err.Error(), "no HTTP request") {
+		t.Errorf("expected an error about HTTP request, got %v", err)
+	}
+}
+
+func TestFormInput(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		errs     interface{}
+		attrs    []interface{}
+		expected string
+		err      bool
+	}{
+		{nil, nil, nil, `<input name="foo">`, false},
+		{1, map[string]string{"foo": ""}, []interface{}{"data-id", 2}, `<input name="foo" value="1" data-id="2">`, false},
+		{nil, map[string]error{"foo": errors.New("bar")}, nil, `<input name="foo" aria-invalid="true">`, false},
+		{nil, nil, []interface{}{"type"}, "", true},
+		{nil, nil, []interface{}{1, "foo"}, "", true},
+		{nil, nil, []interface{}{`x" onclick="`, "foo"}, "", true},
+		{nil, nil, []interface{}{"", "foo"}, "", true},
+		{nil, nil, []interface{}{"onfocus", "alert(1)"}, "", true},
+		{nil, nil, []interface{}{"OnClick", "alert(1)"}, "", true},
+		{nil, nil, []interface{}{"style", "background:url(javascript:alert(1))"}, "", true},
+		{nil, nil, []interface{}{"formaction", "javascript:alert(1)"}, "", true},
+		{nil, nil, []interface{}{"src", "javascript:alert(1)"}, "", true},
+		{nil, nil, []interface{}{"xlink:href", "javascript:alert(1)"}, "", true},
+		{nil, nil, []interface{}{"placeholder", "<b>"}, `<input name="foo" placeholder="&lt;b&gt;">`, false},
+	}
+	for _, test := range tests {
+		actual, err := formInput("foo", test.value, test.errs, test.attrs...)
+		if (err != nil) != test.err || string(actual) != test.expected {
+			t.Errorf("expected %q, got %q, error %v", test.expected, actual, err)
+		}
+	}
+}
+
+func TestFormErrors(t *testing.T) {
+	type field string
+	tests := []struct {
+		errs     interface{}
+		expected []string
+	}{
+		{nil, nil},
+		{"foo", nil},
+		{map[int]string{1: "foo"}, nil},
+		{map[string]string{"bar": "foo"}, nil},
+		{map[string]string{"foo": "bar"}, []string{"bar"}},
+		{map[field]string{"foo": "bar"}, []string{"bar"}},
+		{map[string]interface{}{"foo": nil}, nil},
+		{map[string]interface{}{"foo": []error{errors.New("bar"), errors.New("baz")}}, []string{"bar", "baz"}},
+		{map[string]interface{}{"foo": 1}, []string{"1"}},
+	}
+	for _, test := range tests {
+		if actual := formErrors("foo", test.errs); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("formErrors(%#v): expected %q, got %q", test.errs, test.expected, actual)
+		}
+	}
+}
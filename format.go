@@ -0,0 +1,51 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+)
+
+// UnmarshalFunc is a function that parses the encoded data and stores the
+// result in the value pointed to by v, such as json.Unmarshal.
+type UnmarshalFunc func(data []byte, v interface{}) error
+
+var (
+	formatsMutex sync.RWMutex
+	formats      = map[string]UnmarshalFunc{
+		".json": json.Unmarshal,
+	}
+)
+
+// RegisterFormat registers an unmarshal function for the given file extension,
+// it allows to load data files in formats other than JSON, for example:
+//
+//	views.RegisterFormat(".yaml", yaml.Unmarshal)
+//	views.RegisterFormat(".yml", yaml.Unmarshal)
+//	views.RegisterFormat(".toml", toml.Unmarshal)
+func RegisterFormat(ext string, f UnmarshalFunc) {
+	formatsMutex.Lock()
+	defer formatsMutex.Unlock()
+	formats[strings.ToLower(ext)] = f
+}
+
+func lookupFormat(filename string) (UnmarshalFunc, bool) {
+	formatsMutex.RLock()
+	defer formatsMutex.RUnlock()
+	f, ok := formats[strings.ToLower(path.Ext(filename))]
+	return f, ok
+}
+
+func unmarshalFile(filename string, data []byte, v interface{}) error {
+	f, ok := lookupFormat(filename)
+	if !ok {
+		return fmt.Errorf("unsupported format %q, the formats other than JSON require to be registered by RegisterFormat", path.Ext(filename))
+	}
+	return f(data, v)
+}
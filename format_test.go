@@ -0,0 +1,26 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegisterFormat(t *testing.T) {
+	if _, ok := lookupFormat("foo.custom"); ok {
+		t.Fatal("expected unregistered format")
+	}
+	errCustom := errors.New("custom")
+	RegisterFormat(".CUSTOM", func(data []byte, v interface{}) error {
+		return errCustom
+	})
+	if err := unmarshalFile("foo.custom", nil, nil); err != errCustom {
+		t.Errorf("expected error %s, got %v", errCustom, err)
+	}
+	if err := unmarshalFile("foo.unknown", nil, nil); err == nil {
+		t.Error("expected an error about unsupported format, got nil")
+	}
+}
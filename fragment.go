@@ -0,0 +1,148 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FragmentStore stores the rendered fragments.
+type FragmentStore interface {
+	// Get returns the fragment of the given key, and whether it is found.
+	Get(key string) ([]byte, bool)
+
+	// Set stores the fragment with the given TTL, the fragment never expires
+	// if ttl is non-positive.
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// LRUStore is an in-memory fragment store that evicts the least recently used
+// fragments.
+type LRUStore struct {
+	capacity int
+	mutex    sync.Mutex
+	entries  map[string]*list.Element
+	list     *list.List
+}
+
+// NewLRUStore returns a LRU store with the given capacity.
+func NewLRUStore(capacity int) *LRUStore {
+	return &LRUStore{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		list:     list.New(),
+	}
+}
+
+// Get implements FragmentStore.
+func (s *LRUStore) Get(key string) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*lruEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		s.list.Remove(e)
+		delete(s.entries, key)
+		return nil, false
+	}
+	s.list.MoveToFront(e)
+	return entry.value, true
+}
+
+// Set implements FragmentStore.
+func (s *LRUStore) Set(key string, value []byte, ttl time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entry := &lruEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	if e, ok := s.entries[key]; ok {
+		e.Value = entry
+		s.list.MoveToFront(e)
+		return
+	}
+	s.entries[key] = s.list.PushFront(entry)
+	for s.capacity > 0 && s.list.Len() > s.capacity {
+		e := s.list.Back()
+		s.list.Remove(e)
+		delete(s.entries, e.Value.(*lruEntry).key)
+	}
+}
+
+// FragmentCache registers the cache function that renders a named template
+// or block and caches the output with the given store, an in-memory LRU store
+// with capacity 1000 is used if store is nil.
+//
+//	{{ define "sidebar" }}...{{ end }}
+//	{{ cache "sidebar" 300 . }}
+//	{{ cache "sidebar" 300 . .user.ID .locale }}
+//
+// The arguments are the name of template, TTL in seconds, the data and the
+// optional keys that compose the cache key with the layout, view and name.
+func FragmentCache(store FragmentStore) Option {
+	if store == nil {
+		store = NewLRUStore(1000)
+	}
+	return func(m *Manager) {
+		m.addContextFunc("cache", func(ctx context.Context) interface{} {
+			return func(name string, ttl interface{}, data interface{}, keys ...interface{}) (template.HTML, error) {
+				return m.renderFragment(ctx, store, name, ttl, data, keys)
+			}
+		})
+	}
+}
+
+// fragmentKey returns the cache key of fragment, the parts are prefixed with
+// their length, so that the different parts never produce the same key.
+func fragmentKey(layout, view, name string, keys []interface{}) string {
+	parts := append([]interface{}{layout, view, name}, keys...)
+	b := &strings.Builder{}
+	for _, part := range parts {
+		s := fmt.Sprint(part)
+		b.WriteString(strconv.Itoa(len(s)) + ":" + s)
+	}
+	return b.String()
+}
+
+func (m *Manager) renderFragment(ctx context.Context, store FragmentStore, name string, ttl, data interface{}, keys []interface{}) (template.HTML, error) {
+	seconds, err := toInt(ttl)
+	if err != nil {
+		return "", err
+	}
+	state := renderStateFromContext(ctx)
+	if state == nil || state.tmpl == nil {
+		return "", errors.New("cache: no template is being rendered")
+	}
+	key := fragmentKey(state.layout, state.view, name, keys)
+	if output, ok := store.Get(key); ok {
+		return template.HTML(output), nil
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err = state.tmpl.ExecuteTemplate(buf, name, data); err != nil {
+		return "", err
+	}
+	store.Set(key, buf.Bytes(), time.Duration(seconds)*time.Second)
+	return template.HTML(buf.String()), nil
+}
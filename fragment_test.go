@@ -0,0 +1,92 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFragmentCache(t *testing.T) {
+	store := NewLRUStore(10)
+	m := New(testViewsFileSystem, FragmentCache(store))
+	tests := []struct {
+		data     map[string]interface{}
+		expected string
+	}{
+		{map[string]interface{}{"count": 1, "key": "a"}, "<aside>1</aside>"},
+		{map[string]interface{}{"count": 2, "key": "a"}, "<aside>1</aside>"},
+		{map[string]interface{}{"count": 3, "key": "b"}, "<aside>3</aside>"},
+	}
+	for _, test := range tests {
+		w := bytes.NewBuffer(nil)
+		if err := m.RenderPartial(w, "fragment", test.data); err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		if actual := strings.TrimSpace(w.String()); actual != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+	if _, ok := store.Get(fragmentKey("", "fragment", "sidebar", []interface{}{"a"})); !ok {
+		t.Error("expected the fragment to be cached")
+	}
+
+	if err := m.RenderPartial(bytes.NewBuffer(nil), "fragment", map[string]interface{}{"key": "c", "count": struct{}{}}); err != nil {
+		t.Errorf("failed to render: %s", err)
+	}
+	if err := m.RenderPartial(bytes.NewBuffer(nil), "fragment", map[string]interface{}{"key": "d"}); err != nil {
+		t.Errorf("failed to render: %s", err)
+	}
+}
+
+func TestFragmentCacheErrors(t *testing.T) {
+	m := New(testViewsFileSystem, FragmentCache(nil))
+	if _, err := m.renderFragment(context.Background(), NewLRUStore(1), "sidebar", "invalid", nil, nil); err == nil {
+		t.Error("expected an error about invalid TTL, got nil")
+	}
+}
+
+func TestFragmentKey(t *testing.T) {
+	keys := []string{
+		fragmentKey("", "foo", "sidebar", nil),
+		fragmentKey("", "bar", "sidebar", nil),
+		fragmentKey("main", "foo", "sidebar", nil),
+		fragmentKey("", "foo", "sidebar", []interface{}{"a:b", "c"}),
+		fragmentKey("", "foo", "sidebar", []interface{}{"a", "b:c"}),
+		fragmentKey("", "foo", "sidebar", []interface{}{"a", "b", "c"}),
+	}
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if seen[key] {
+			t.Errorf("duplicate key %q", key)
+		}
+		seen[key] = true
+	}
+}
+
+func TestLRUStore(t *testing.T) {
+	s := NewLRUStore(2)
+	s.Set("foo", []byte("foo"), 0)
+	s.Set("bar", []byte("bar"), 0)
+	s.Get("foo")
+	s.Set("baz", []byte("baz"), 0)
+	if _, ok := s.Get("bar"); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	for _, key := range []string{"foo", "baz"} {
+		if _, ok := s.Get(key); !ok {
+			t.Errorf("expected entry %q to be kept", key)
+		}
+	}
+
+	s.Set("foo", []byte("qux"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, ok := s.Get("foo"); ok {
+		t.Error("expected the entry to be expired")
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Helpers is an option that registers the following helper functions, the
+// functions registered by FuncMap and AddFunc take precedence over them
+// regardless of the order of options.
+//
+//	dict "key" value ...          // map[string]interface{}
+//	list value ...                // []interface{}
+//	.name | default "guest"       // returns the default value if the value is empty
+//	.tags | join ", "             // joins the elements of a slice
+//	.name | upper                 // upper-case
+//	.summary | truncate 100       // truncates to 100 characters, appends "…" if truncated
+//	.createdAt | dateFormat "2006-01-02"
+//	json .data                    // encodes as JSON
+//	safeHTML .content             // marks the trusted content as safe HTML
+//	add 1 2, sub 3 1              // integer arithmetic
+//	range seq 5, range seq 2 5    // 1..5, 2..5, up to MaxSeqLength integers
+//
+// The output of helpers is escaped as usual, except that json returns
+// template.JS, which is inserted as is in scripts, but is escaped in HTML
+// text and attributes, and safeHTML disables escaping, so that it must never
+// be used with untrusted content.
+func Helpers() Option {
+	return func(m *Manager) {
+		m.helpers = template.FuncMap{
+			"dict":       dict,
+			"list":       listOf,
+			"default":    defaultValue,
+			"join":       join,
+			"upper":      strings.ToUpper,
+			"truncate":   truncate,
+			"dateFormat": dateFormat,
+			"json":       toJSON,
+			"safeHTML":   safeHTML,
+			"add":        add,
+			"sub":        sub,
+			"seq":        seq,
+		}
+	}
+}
+
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	return pairsToMap("dict", pairs)
+}
+
+func listOf(values ...interface{}) []interface{} {
+	return values
+}
+
+func defaultValue(def, value interface{}) interface{} {
+	if isEmpty(value) {
+		return def
+	}
+	return value
+}
+
+// isEmpty reports whether the value is nil, zero value or empty collection.
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return reflect.DeepEqual(value, reflect.Zero(v.Type()).Interface())
+}
+
+func join(sep string, values interface{}) (string, error) {
+	if s, ok := values.([]string); ok {
+		return strings.Join(s, sep), nil
+	}
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: unable to join %T", values)
+	}
+	s := make([]string, v.Len())
+	for i := range s {
+		s[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(s, sep), nil
+}
+
+func truncate(length int, s string) string {
+	runes := []rune(s)
+	if length < 0 || len(runes) <= length {
+		return s
+	}
+	return string(runes[:length]) + "…"
+}
+
+func dateFormat(layout string, value interface{}) (string, error) {
+	switch t := value.(type) {
+	case time.Time:
+		return t.Format(layout), nil
+	case *time.Time:
+		if t == nil {
+			return "", nil
+		}
+		return t.Format(layout), nil
+	}
+	return "", fmt.Errorf("dateFormat: unable to format %T", value)
+}
+
+func toJSON(value interface{}) (template.JS, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return template.JS(b), nil
+}
+
+func safeHTML(s string) template.HTML {
+	return template.HTML(s)
+}
+
+func add(a, b interface{}) (int, error) {
+	x, y, err := toInts(a, b)
+	return x + y, err
+}
+
+func sub(a, b interface{}) (int, error) {
+	x, y, err := toInts(a, b)
+	return x - y, err
+}
+
+func toInts(a, b interface{}) (int, int, error) {
+	x, err := toInt(a)
+	if err != nil {
+		return 0, 0, err
+	}
+	y, err := toInt(b)
+	return x, y, err
+}
+
+// MaxSeqLength is the max length of sequence that returned by seq.
+const MaxSeqLength = 10000
+
+// seq returns the sequence of integers, seq n returns 1 to n, and seq m n
+// returns m to n, the sequence is descending if m is greater than n.
+func seq(args ...interface{}) ([]int, error) {
+	start, end := 1, 0
+	var err error
+	switch len(args) {
+	case 1:
+		end, err = toInt(args[0])
+	case 2:
+		start, end, err = toInts(args[0], args[1])
+	default:
+		return nil, errors.New("seq: requires one or two arguments")
+	}
+	if err != nil {
+		return nil, err
+	}
+	step := 1
+	if start > end && len(args) == 2 {
+		step = -1
+	}
+	// the distance is computed in unsigned integers to avoid overflows.
+	distance := uint64(end) - uint64(start)
+	if step < 0 {
+		distance = uint64(start) - uint64(end)
+	}
+	if start <= end || step < 0 {
+		if distance >= MaxSeqLength {
+			return nil, fmt.Errorf("seq: length exceeds the limit %d", MaxSeqLength)
+		}
+	}
+	values := []int{}
+	for i := start; (step > 0 && i <= end) || (step < 0 && i >= end); i += step {
+		values = append(values, i)
+	}
+	return values, nil
+}
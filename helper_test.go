@@ -0,0 +1,183 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"html/template"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHelpers(t *testing.T) {
+	date := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	data := map[string]interface{}{
+		"text": "<hello world>",
+		"date": date,
+		"data": map[string]interface{}{"tag": "</script>"},
+	}
+	expected := "FOO guest 1,2 &lt;hell… 2020-01-02 3 2 123\n" +
+		`<p><b>bold</b> &lt;hello world&gt;</p><script>var data = {"tag":"\u003c/script\u003e"};</script>` + "\n"
+
+	m := New(testViewsFileSystem, Helpers())
+	w := bytes.NewBuffer(nil)
+	if err := m.RenderPartial(w, "helpers", data); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	if actual := w.String(); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	// user functions take precedence over helpers.
+	upper := func(s string) string { return "upper" }
+	for _, m := range []*Manager{
+		New(testViewsFileSystem, FuncMap(template.FuncMap{"upper": upper}), Helpers()),
+		New(testViewsFileSystem, Helpers(), FuncMap(template.FuncMap{"upper": upper})),
+	} {
+		w.Reset()
+		if err := m.RenderPartial(w, "helpers", data); err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		if actual := w.String(); actual[:5] != "upper" {
+			t.Errorf("expected user function is used, got %q", actual)
+		}
+	}
+}
+
+func TestDefaultValue(t *testing.T) {
+	var nilPtr *int
+	one := 1
+	tests := []struct {
+		value    interface{}
+		expected interface{}
+	}{
+		{nil, "default"},
+		{"", "default"},
+		{0, "default"},
+		{false, "default"},
+		{[]int{}, "default"},
+		{map[string]int{}, "default"},
+		{nilPtr, "default"},
+		{struct{}{}, "default"},
+		{"foo", "foo"},
+		{1, 1},
+		{true, true},
+		{&one, &one},
+	}
+	for _, test := range tests {
+		if actual := defaultValue("default", test.value); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("default(%#v): expected %#v, got %#v", test.value, test.expected, actual)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		values   interface{}
+		expected string
+		err      bool
+	}{
+		{[]string{"a", "b"}, "a, b", false},
+		{[]interface{}{1, "b"}, "1, b", false},
+		{[2]int{1, 2}, "1, 2", false},
+		{"a", "", true},
+	}
+	for _, test := range tests {
+		actual, err := join(", ", test.values)
+		if (err != nil) != test.err || actual != test.expected {
+			t.Errorf("join(%#v): expected %q, got %q, error %v", test.values, test.expected, actual, err)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		length   int
+		s        string
+		expected string
+	}{
+		{3, "foobar", "foo…"},
+		{6, "foobar", "foobar"},
+		{-1, "foobar", "foobar"},
+		{2, "你好世界", "你好…"},
+	}
+	for _, test := range tests {
+		if actual := truncate(test.length, test.s); actual != test.expected {
+			t.Errorf("truncate(%d, %q): expected %q, got %q", test.length, test.s, test.expected, actual)
+		}
+	}
+}
+
+func TestDateFormat(t *testing.T) {
+	date := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	var nilTime *time.Time
+	tests := []struct {
+		value    interface{}
+		expected string
+		err      bool
+	}{
+		{date, "2020-01-02", false},
+		{&date, "2020-01-02", false},
+		{nilTime, "", false},
+		{"2020-01-02", "", true},
+	}
+	for _, test := range tests {
+		actual, err := dateFormat("2006-01-02", test.value)
+		if (err != nil) != test.err || actual != test.expected {
+			t.Errorf("dateFormat(%#v): expected %q, got %q, error %v", test.value, test.expected, actual, err)
+		}
+	}
+}
+
+func TestHelpersErrors(t *testing.T) {
+	if _, err := dict("foo"); err == nil {
+		t.Error("expected an error, got nil")
+	}
+	if _, err := toJSON(func() {}); err == nil {
+		t.Error("expected an error, got nil")
+	}
+	if _, err := add("a", 1); err == nil {
+		t.Error("expected an error, got nil")
+	}
+	if _, err := sub(1, "b"); err == nil {
+		t.Error("expected an error, got nil")
+	}
+}
+
+func TestSeq(t *testing.T) {
+	tests := []struct {
+		args     []interface{}
+		expected []int
+		err      bool
+	}{
+		{[]interface{}{3}, []int{1, 2, 3}, false},
+		{[]interface{}{0}, []int{}, false},
+		{[]interface{}{2, 4}, []int{2, 3, 4}, false},
+		{[]interface{}{4, 2}, []int{4, 3, 2}, false},
+		{[]interface{}{}, nil, true},
+		{[]interface{}{1, 2, 3}, nil, true},
+		{[]interface{}{"a"}, nil, true},
+		{[]interface{}{MaxSeqLength}, nil, false},
+		{[]interface{}{MaxSeqLength + 1}, nil, true},
+		{[]interface{}{1, MaxSeqLength + 1}, nil, true},
+		{[]interface{}{MaxSeqLength, -1}, nil, true},
+		{[]interface{}{int64(math.MinInt64), int64(math.MaxInt64)}, nil, true},
+		{[]interface{}{int64(math.MaxInt64), int64(math.MinInt64)}, nil, true},
+	}
+	for _, test := range tests {
+		actual, err := seq(test.args...)
+		if test.expected == nil && !test.err {
+			if err != nil || len(actual) != MaxSeqLength {
+				t.Errorf("seq(%v): expected %d integers, got %d, error %v", test.args, MaxSeqLength, len(actual), err)
+			}
+			continue
+		}
+		if (err != nil) != test.err || !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("seq(%v): expected %v, got %v, error %v", test.args, test.expected, actual, err)
+		}
+	}
+}
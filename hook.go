@@ -0,0 +1,86 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+)
+
+// RenderEvent is the event of rendering a view.
+type RenderEvent struct {
+	// Context is the render context.
+	Context context.Context
+
+	// Layout is the layout, it is empty if the view is rendered without layout.
+	Layout string
+	View   string
+	Data   interface{}
+
+	// Output is the rendered output, it is only available in after hooks.
+	Output []byte
+
+	// Duration is the duration of rendering, it is only available in after hooks.
+	Duration time.Duration
+
+	// Err is the error occurred during rendering, it is only available in after hooks.
+	Err error
+}
+
+// Hook is a function that invoked before or after rendering.
+type Hook func(e *RenderEvent) error
+
+// BeforeRender registers a hook that invoked before rendering, the hook is
+// able to modify the context, layout, view and data of event, and abort the
+// rendering by returning an error.
+func (m *Manager) BeforeRender(hook Hook) {
+	m.beforeHooks = append(m.beforeHooks, hook)
+}
+
+// AfterRender registers a hook that invoked after rendering, even though the
+// rendering failed. The hook receives the output, duration and error, it is
+// able to post-process the output, the output is written only if all of after
+// hooks succeed and the rendering succeeded.
+//
+// The output is buffered if there are any after hooks.
+func (m *Manager) AfterRender(hook Hook) {
+	m.afterHooks = append(m.afterHooks, hook)
+}
+
+func (m *Manager) renderWithHooks(ctx context.Context, w io.Writer, layout, view string, data interface{}) error {
+	e := &RenderEvent{Context: ctx, Layout: layout, View: view, Data: data}
+	start := time.Now()
+	for _, hook := range m.beforeHooks {
+		if e.Err = hook(e); e.Err != nil {
+			break
+		}
+	}
+	if len(m.afterHooks) == 0 {
+		if e.Err != nil {
+			return e.Err
+		}
+		return m.execute(e.Context, w, e.Layout, e.View, e.Data)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if e.Err == nil {
+		e.Err = m.execute(e.Context, buf, e.Layout, e.View, e.Data)
+	}
+	e.Output = buf.Bytes()
+	e.Duration = time.Since(start)
+	for _, hook := range m.afterHooks {
+		if err := hook(e); err != nil {
+			return err
+		}
+	}
+	if e.Err != nil {
+		return e.Err
+	}
+
+	_, err := w.Write(e.Output)
+	return err
+}
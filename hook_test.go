@@ -0,0 +1,81 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestManagerBeforeRender(t *testing.T) {
+	m := New(testViewsFileSystem)
+	m.BeforeRender(func(e *RenderEvent) error {
+		e.Data = map[string]interface{}{"appName": "foo"}
+		return nil
+	})
+	m.BeforeRender(func(e *RenderEvent) error {
+		e.View = "shared"
+		return nil
+	})
+	w := bytes.NewBuffer(nil)
+	if err := m.RenderPartial(w, "nonexistent", nil); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+
+	errAbort := errors.New("abort")
+	m.BeforeRender(func(e *RenderEvent) error {
+		return errAbort
+	})
+	w.Reset()
+	if err := m.RenderPartial(w, "shared", nil); err != errAbort {
+		t.Errorf("expected error %s, got %v", errAbort, err)
+	}
+	if w.Len() != 0 {
+		t.Errorf("expected no output, got %q", w.String())
+	}
+}
+
+func TestManagerAfterRender(t *testing.T) {
+	m := New(testViewsFileSystem)
+	var events []RenderEvent
+	m.AfterRender(func(e *RenderEvent) error {
+		events = append(events, *e)
+		e.Output = bytes.ToUpper(e.Output)
+		return nil
+	})
+	m.Share("appName", "foo")
+
+	w := bytes.NewBuffer(nil)
+	if err := m.RenderPartial(w, "shared", nil); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	if actual := string(bytes.TrimSpace(w.Bytes())); actual != "FOO" {
+		t.Errorf("expected post-processed output %q, got %q", "FOO", actual)
+	}
+	if len(events) != 1 || events[0].View != "shared" || events[0].Duration <= 0 || events[0].Err != nil {
+		t.Errorf("unexpected event %+v", events)
+	}
+
+	w.Reset()
+	if err := m.RenderPartial(w, "nonexistent", nil); err == nil {
+		t.Error("expected an error about view file not found, got nil")
+	}
+	if len(events) != 2 || events[1].Err == nil {
+		t.Errorf("expected after hooks to receive the error, got %+v", events)
+	}
+
+	errAfter := errors.New("after")
+	m.AfterRender(func(e *RenderEvent) error {
+		return errAfter
+	})
+	w.Reset()
+	if err := m.RenderPartial(w, "shared", nil); err != errAfter {
+		t.Errorf("expected error %s, got %v", errAfter, err)
+	}
+	if w.Len() != 0 {
+		t.Errorf("expected no output, got %q", w.String())
+	}
+}
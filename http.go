@@ -0,0 +1,164 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LastModified is an option that sets the Last-Modified header of HTTP
+// renders, with the latest modification time of layout, partials and view
+// files. Note that the data is not taken into account.
+func LastModified(enabled bool) Option {
+	return func(m *Manager) {
+		m.lastModified = enabled
+	}
+}
+
+// RenderHTTP renders the view with the default layout and writes the response,
+// see RenderLayoutHTTP.
+func (m *Manager) RenderHTTP(w http.ResponseWriter, r *http.Request, view string, data interface{}) error {
+	return m.renderHTTP(w, r, m.viewLayout(view), view, data)
+}
+
+// RenderLayoutHTTP renders the view with the given layout and writes the
+// response, the Content-Type defaults to "text/plain; charset=utf-8" for the
+// text engine, and "text/html; charset=utf-8" for the others. The output is
+// buffered, and a strong ETag computed from output is set, it responds 304 Not
+// Modified if the If-None-Match header matches the ETag. Nothing is written if
+// rendering failed, so that the caller is able to respond an error page.
+func (m *Manager) RenderLayoutHTTP(w http.ResponseWriter, r *http.Request, layout, view string, data interface{}) error {
+	return m.renderHTTP(w, r, layout, view, data)
+}
+
+// RenderPartialHTTP renders the view without layout and writes the response,
+// see RenderLayoutHTTP.
+func (m *Manager) RenderPartialHTTP(w http.ResponseWriter, r *http.Request, view string, data interface{}) error {
+	return m.renderHTTP(w, r, "", view, data)
+}
+
+func (m *Manager) renderHTTP(w http.ResponseWriter, r *http.Request, layout, view string, data interface{}) error {
+	ctx := context.WithValue(r.Context(), requestKey{}, r)
+	var nonce string
+	if m.cspPolicy != "" {
+		var err error
+		if ctx, nonce, err = withNonce(ctx); err != nil {
+			return err
+		}
+	}
+	var flashes []Flash
+	if m.flashStore != nil {
+		var err error
+		if flashes, err = m.flashStore.Flashes(r); err != nil {
+			return err
+		}
+		// the flashes are only injected into the data of map, and are kept
+		// for the next render otherwise. The FlashesKey is reserved, so that
+		// the flashes override the value of the same key in data.
+		switch data.(type) {
+		case nil, map[string]interface{}:
+			if len(flashes) > 0 {
+				merged, err := mergeData(data, nil)
+				if err != nil {
+					return err
+				}
+				merged[FlashesKey] = flashes
+				data = merged
+			}
+		default:
+			flashes = nil
+		}
+	}
+	result := &httpResult{}
+	ctx = context.WithValue(ctx, httpResultKey{}, result)
+	buf := bytes.NewBuffer(nil)
+	if err := m.render(ctx, buf, layout, view, data); err != nil {
+		return err
+	}
+
+	header := w.Header()
+	if m.cspPolicy != "" {
+		header.Set("Content-Security-Policy", m.cspHeader(nonce))
+	}
+	if header.Get("Content-Type") == "" {
+		if _, ok := m.engine.(textEngine); ok {
+			header.Set("Content-Type", "text/plain; charset=utf-8")
+		} else {
+			header.Set("Content-Type", "text/html; charset=utf-8")
+		}
+	}
+	if m.lastModified && !result.modTime.IsZero() {
+		header.Set("Last-Modified", result.modTime.UTC().Format(http.TimeFormat))
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	etag := hex.EncodeToString(sum[:16])
+
+	body := buf.Bytes()
+	if m.compress && header.Get("Content-Encoding") == "" {
+		addVary(header, "Accept-Encoding")
+		if len(body) >= m.compressMinSize {
+			if e, ok := m.negotiateEncoding(r.Header.Get("Accept-Encoding")); ok {
+				compressed := bytes.NewBuffer(nil)
+				if err := e.encoder(compressed, body); err != nil {
+					return err
+				}
+				body = compressed.Bytes()
+				header.Set("Content-Encoding", e.name)
+				// the representations of different encodings have different ETags.
+				etag += "-" + e.name
+			}
+		}
+	}
+	etag = `"` + etag + `"`
+	header.Set("ETag", etag)
+
+	if len(flashes) > 0 {
+		if err := m.flashStore.Consume(w, r); err != nil {
+			return err
+		}
+	}
+
+	if (r.Method == http.MethodGet || r.Method == http.MethodHead) && matchETag(r.Header.Get("If-None-Match"), etag) {
+		delete(header, "Content-Type")
+		delete(header, "Content-Encoding")
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		_, err := w.Write(body)
+		return err
+	}
+	return nil
+}
+
+// httpResult is the result of HTTP render, which is filled by execute.
+type httpResult struct {
+	// modTime is the modification time of the rendered template.
+	modTime time.Time
+}
+
+type httpResultKey struct{}
+
+// matchETag reports whether the If-None-Match header matches the ETag, it uses
+// the weak comparison as RFC 7232 defined.
+func matchETag(header, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
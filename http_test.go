@@ -0,0 +1,149 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRenderHTTP(t *testing.T) {
+	m := New(testViewsFileSystem)
+	data := map[string]interface{}{"title": "foo"}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	resp := httptest.NewRecorder()
+	if err := m.RenderPartialHTTP(resp, req, "page", data); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	if resp.Code != http.StatusOK {
+		t.Errorf("expected status code %d, got %d", http.StatusOK, resp.Code)
+	}
+	if body := resp.Body.String(); body != "<h1>foo</h1>\n" {
+		t.Errorf("unexpected body %q", body)
+	}
+	if contentType := resp.Header().Get("Content-Type"); contentType != "text/html; charset=utf-8" {
+		t.Errorf("unexpected content type %q", contentType)
+	}
+	if lastModified := resp.Header().Get("Last-Modified"); lastModified != "" {
+		t.Errorf("expected no Last-Modified header, got %q", lastModified)
+	}
+	etag := resp.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag header")
+	}
+
+	tests := []struct {
+		method      string
+		ifNoneMatch string
+		data        map[string]interface{}
+		code        int
+		body        string
+	}{
+		{http.MethodGet, etag, data, http.StatusNotModified, ""},
+		{http.MethodGet, `"foo", W/` + etag, data, http.StatusNotModified, ""},
+		{http.MethodGet, "*", data, http.StatusNotModified, ""},
+		{http.MethodHead, etag, data, http.StatusNotModified, ""},
+		{http.MethodGet, etag, map[string]interface{}{"title": "bar"}, http.StatusOK, "<h1>bar</h1>\n"},
+		{http.MethodHead, "", data, http.StatusOK, ""},
+		{http.MethodPost, etag, data, http.StatusOK, "<h1>foo</h1>\n"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, "/", nil)
+		if test.ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", test.ifNoneMatch)
+		}
+		resp := httptest.NewRecorder()
+		if err := m.RenderPartialHTTP(resp, req, "page", test.data); err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		if resp.Code != test.code {
+			t.Errorf("%s %q: expected status code %d, got %d", test.method, test.ifNoneMatch, test.code, resp.Code)
+		}
+		if body := resp.Body.String(); body != test.body {
+			t.Errorf("%s %q: expected body %q, got %q", test.method, test.ifNoneMatch, test.body, body)
+		}
+	}
+}
+
+func TestRenderHTTPContentType(t *testing.T) {
+	tests := []struct {
+		manager     *Manager
+		contentType string
+	}{
+		{New(testViewsFileSystem), "text/html; charset=utf-8"},
+		{NewText(testViewsFileSystem), "text/plain; charset=utf-8"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		resp := httptest.NewRecorder()
+		if err := test.manager.RenderPartialHTTP(resp, req, "page", nil); err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		if actual := resp.Header().Get("Content-Type"); actual != test.contentType {
+			t.Errorf("expected content type %q, got %q", test.contentType, actual)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	resp := httptest.NewRecorder()
+	resp.Header().Set("Content-Type", "application/xml")
+	if err := New(testViewsFileSystem).RenderPartialHTTP(resp, req, "page", nil); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	if actual := resp.Header().Get("Content-Type"); actual != "application/xml" {
+		t.Errorf("expected the content type is kept, got %q", actual)
+	}
+}
+
+func TestRenderHTTPLastModified(t *testing.T) {
+	collector := &fakeCollector{}
+	m := New(testViewsFileSystem, LastModified(true), Cache(false), Metrics(collector))
+	m.AddLayout("main")
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	resp := httptest.NewRecorder()
+	if err := m.RenderLayoutHTTP(resp, req, "main", "content", nil); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+
+	var expected time.Time
+	for _, name := range []string{"layouts/main.tmpl", "content.tmpl"} {
+		info, err := os.Stat(filepath.Join("testdata", "views", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.ModTime().After(expected) {
+			expected = info.ModTime()
+		}
+	}
+	if actual := resp.Header().Get("Last-Modified"); actual != expected.UTC().Format(http.TimeFormat) {
+		t.Errorf("unexpected Last-Modified header %q", actual)
+	}
+	expectedEvents := []string{"cache main content false", "compile main content true", "render main content 16 true"}
+	if !reflect.DeepEqual(collector.events, expectedEvents) {
+		t.Errorf("expected events %q, got %q", expectedEvents, collector.events)
+	}
+}
+
+func TestRenderHTTPError(t *testing.T) {
+	m := New(testViewsFileSystem)
+	m.BeforeRender(func(e *RenderEvent) error {
+		return errors.New("aborted")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	resp := httptest.NewRecorder()
+	if err := m.RenderHTTP(resp, req, "page", nil); err == nil || err.Error() != "aborted" {
+		t.Errorf("expected error aborted, got %v", err)
+	}
+	if resp.Body.Len() != 0 || len(resp.Header()) != 0 {
+		t.Error("expected nothing is written")
+	}
+}
@@ -0,0 +1,430 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PluralRule returns the index of plural form for the given quantity.
+type PluralRule func(n int) int
+
+var pluralRules = map[string]PluralRule{
+	"fr": func(n int) int {
+		if n > 1 {
+			return 1
+		}
+		return 0
+	},
+	"zh": pluralRuleOneForm,
+	"ja": pluralRuleOneForm,
+	"ko": pluralRuleOneForm,
+	"vi": pluralRuleOneForm,
+	"th": pluralRuleOneForm,
+	"id": pluralRuleOneForm,
+	"ru": pluralRuleSlavic,
+	"uk": pluralRuleSlavic,
+	"be": pluralRuleSlavic,
+	"sr": pluralRuleSlavic,
+	"hr": pluralRuleSlavic,
+	"bs": pluralRuleSlavic,
+	"pl": func(n int) int {
+		if n == 1 {
+			return 0
+		}
+		if n%10 >= 2 && n%10 <= 4 && (n%100 < 10 || n%100 >= 20) {
+			return 1
+		}
+		return 2
+	},
+	"cs": pluralRuleCzech,
+	"sk": pluralRuleCzech,
+}
+
+func pluralRuleDefault(n int) int {
+	if n != 1 {
+		return 1
+	}
+	return 0
+}
+
+func pluralRuleOneForm(n int) int {
+	return 0
+}
+
+func pluralRuleSlavic(n int) int {
+	if n%10 == 1 && n%100 != 11 {
+		return 0
+	}
+	if n%10 >= 2 && n%10 <= 4 && (n%100 < 10 || n%100 >= 20) {
+		return 1
+	}
+	return 2
+}
+
+func pluralRuleCzech(n int) int {
+	if n == 1 {
+		return 0
+	}
+	if n >= 2 && n <= 4 {
+		return 1
+	}
+	return 2
+}
+
+// Translator translates messages with the loaded catalogs.
+type Translator struct {
+	fallback string
+	mutex    sync.RWMutex
+	catalogs map[string]map[string][]string
+	rules    map[string]PluralRule
+}
+
+// NewTranslator returns a translator with the given fallback locale.
+func NewTranslator(fallback string) *Translator {
+	return &Translator{
+		fallback: normalizeLocale(fallback),
+		catalogs: make(map[string]map[string][]string),
+		rules:    make(map[string]PluralRule),
+	}
+}
+
+// AddMessages adds messages to the catalog of the given locale, the value of
+// messages is a list of plural forms, the messages that have no plural forms
+// contains only one element.
+func (t *Translator) AddMessages(locale string, messages map[string][]string) {
+	locale = normalizeLocale(locale)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	catalog, ok := t.catalogs[locale]
+	if !ok {
+		catalog = make(map[string][]string)
+		t.catalogs[locale] = catalog
+	}
+	for key, forms := range messages {
+		catalog[key] = forms
+	}
+}
+
+// SetPluralRule sets the plural rule of the given locale.
+func (t *Translator) SetPluralRule(locale string, rule PluralRule) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.rules[normalizeLocale(locale)] = rule
+}
+
+// Load loads a catalog file, the locale is determined by the file name,
+// such as "fr.json", "pt-BR.po", "en.yaml".
+//
+// PO and JSON files are supported out of box, other formats can be
+// registered by RegisterFormat. The JSON format looks like:
+//
+//	{
+//		"hello": "Hello {name}",
+//		"apples": ["{count} apple", "{count} apples"]
+//	}
+func (t *Translator) Load(fs http.FileSystem, filename string) error {
+	content, err := readFile(fs, filename)
+	if err != nil {
+		return err
+	}
+	base := path.Base(filename)
+	locale := strings.TrimSuffix(base, path.Ext(base))
+	var messages map[string][]string
+	if strings.EqualFold(path.Ext(filename), ".po") {
+		messages, err = parsePO(content)
+	} else {
+		messages, err = parseCatalog(filename, content)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load catalog %q: %s", filename, err)
+	}
+	t.AddMessages(locale, messages)
+	return nil
+}
+
+// LoadDir loads all catalog files in the given directory.
+func (t *Translator) LoadDir(fs http.FileSystem, dir string) error {
+	d, err := fs.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	infos, err := d.Readdir(-1)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+		ext := path.Ext(info.Name())
+		if _, ok := lookupFormat(ext); !ok && !strings.EqualFold(ext, ".po") {
+			continue
+		}
+		if err = t.Load(fs, path.Join(dir, info.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Translate translates the message of the given key, args is either a map or
+// key-value pairs that used to replace the placeholders, such as "{name}".
+func (t *Translator) Translate(locale, key string, args ...interface{}) string {
+	forms, _ := t.lookup(locale, key)
+	if len(forms) == 0 {
+		return interpolate(key, args)
+	}
+	return interpolate(forms[0], args)
+}
+
+// TranslatePlural translates the message of the given key with the plural
+// form of n, the placeholder "{count}" is replaced with n.
+func (t *Translator) TranslatePlural(locale, key string, n int, args ...interface{}) string {
+	forms, matched := t.lookup(locale, key)
+	args = append([]interface{}{"count", n}, args...)
+	if len(forms) == 0 {
+		return interpolate(key, args)
+	}
+	idx := t.pluralRule(matched)(n)
+	if idx < 0 || idx >= len(forms) {
+		idx = len(forms) - 1
+	}
+	return interpolate(forms[idx], args)
+}
+
+func (t *Translator) lookup(locale, key string) ([]string, string) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	for _, l := range t.candidates(locale) {
+		if forms, ok := t.catalogs[l][key]; ok {
+			return forms, l
+		}
+	}
+	return nil, ""
+}
+
+func (t *Translator) candidates(locale string) []string {
+	locale = normalizeLocale(locale)
+	locales := []string{}
+	if locale != "" {
+		locales = append(locales, locale)
+		if i := strings.IndexByte(locale, '-'); i > 0 {
+			locales = append(locales, locale[:i])
+		}
+	}
+	return append(locales, t.fallback)
+}
+
+func (t *Translator) pluralRule(locale string) PluralRule {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	for _, l := range []string{locale, strings.SplitN(locale, "-", 2)[0]} {
+		if rule, ok := t.rules[l]; ok {
+			return rule
+		}
+		if rule, ok := pluralRules[l]; ok {
+			return rule
+		}
+	}
+	return pluralRuleDefault
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.Replace(locale, "_", "-", -1))
+}
+
+func interpolate(s string, args []interface{}) string {
+	if len(args) == 0 || !strings.Contains(s, "{") {
+		return s
+	}
+	params := map[string]interface{}{}
+	for i := 0; i < len(args); i += 2 {
+		if m, ok := args[i].(map[string]interface{}); ok {
+			for name, value := range m {
+				params[name] = value
+			}
+			i--
+			continue
+		}
+		if i+1 < len(args) {
+			params[fmt.Sprint(args[i])] = args[i+1]
+		}
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(s)
+}
+
+func parseCatalog(filename string, content []byte) (map[string][]string, error) {
+	var raw map[string]interface{}
+	if err := unmarshalFile(filename, content, &raw); err != nil {
+		return nil, err
+	}
+	messages := make(map[string][]string, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case string:
+			messages[key] = []string{v}
+		case []interface{}:
+			forms := make([]string, len(v))
+			for i, form := range v {
+				forms[i] = fmt.Sprint(form)
+			}
+			messages[key] = forms
+		default:
+			return nil, fmt.Errorf("invalid message %q", key)
+		}
+	}
+	return messages, nil
+}
+
+// parsePO parses the gettext PO file, contexts and comments are ignored.
+func parsePO(content []byte) (map[string][]string, error) {
+	messages := make(map[string][]string)
+	var (
+		id, plural string
+		forms      []string
+		last       *string
+	)
+	flush := func() {
+		if id != "" && len(forms) > 0 && forms[0] != "" {
+			messages[id] = forms
+		}
+		id, plural, forms, last = "", "", nil, nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, `"`) {
+			if last == nil {
+				return nil, fmt.Errorf("line %d: unexpected string", lineNo)
+			}
+			s, err := strconv.Unquote(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", lineNo, err)
+			}
+			*last += s
+			continue
+		}
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: invalid syntax", lineNo)
+		}
+		value, err := strconv.Unquote(strings.TrimSpace(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNo, err)
+		}
+		switch keyword := fields[0]; {
+		case keyword == "msgctxt":
+			flush()
+			last = new(string)
+		case keyword == "msgid":
+			if len(forms) > 0 {
+				flush()
+			}
+			id = value
+			last = &id
+		case keyword == "msgid_plural":
+			plural = value
+			last = &plural
+		case keyword == "msgstr":
+			forms = []string{value}
+			last = &forms[0]
+		case strings.HasPrefix(keyword, "msgstr["):
+			idx, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(keyword, "msgstr["), "]"))
+			if err != nil || idx != len(forms) {
+				return nil, fmt.Errorf("line %d: invalid plural index", lineNo)
+			}
+			forms = append(forms, value)
+			last = &forms[idx]
+		default:
+			return nil, fmt.Errorf("line %d: unknown keyword %q", lineNo, keyword)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return messages, nil
+}
+
+type localeKey struct{}
+
+// WithLocale returns a copy of ctx with the given locale, which is used to
+// translate messages while rendering with the context.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+func localeFromContext(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
+
+// I18n registers the translation functions with the given translator.
+//
+//	{{ t "hello" "name" .name }}
+//	{{ tn "apples" .count }}
+//	{{ locale }}
+//
+// The locale is picked from the render context, see WithLocale, and falls
+// back to the translator's fallback locale.
+func I18n(t *Translator) Option {
+	return func(m *Manager) {
+		m.addContextFunc("locale", func(ctx context.Context) interface{} {
+			return func() string {
+				if locale := localeFromContext(ctx); locale != "" {
+					return locale
+				}
+				return t.fallback
+			}
+		})
+		m.addContextFunc("t", func(ctx context.Context) interface{} {
+			return func(key string, args ...interface{}) string {
+				return t.Translate(localeFromContext(ctx), key, args...)
+			}
+		})
+		m.addContextFunc("tn", func(ctx context.Context) interface{} {
+			return func(key string, n interface{}, args ...interface{}) (string, error) {
+				count, err := toInt(n)
+				if err != nil {
+					return "", err
+				}
+				return t.TranslatePlural(localeFromContext(ctx), key, count, args...), nil
+			}
+		})
+	}
+}
+
+func toInt(v interface{}) (int, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return int(rv.Float()), nil
+	case reflect.String:
+		return strconv.Atoi(rv.String())
+	}
+	return 0, fmt.Errorf("unable to convert %T to int", v)
+}
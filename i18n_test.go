@@ -0,0 +1,158 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+)
+
+func newTestTranslator(t *testing.T) *Translator {
+	tr := NewTranslator("en")
+	if err := tr.LoadDir(testDataFileSystem, "/i18n"); err != nil {
+		t.Fatalf("failed to load catalogs: %s", err)
+	}
+	return tr
+}
+
+func TestTranslatorTranslate(t *testing.T) {
+	tr := newTestTranslator(t)
+	tests := []struct {
+		locale   string
+		key      string
+		args     []interface{}
+		expected string
+	}{
+		{"en", "hello", []interface{}{"name", "foo"}, "Hello foo"},
+		{"fr", "hello", []interface{}{map[string]interface{}{"name": "foo"}}, "Bonjour foo"},
+		{"fr_CA", "hello", []interface{}{"name", "foo"}, "Bonjour foo"},
+		{"de", "hello", []interface{}{"name", "foo"}, "Hello foo"},
+		{"fr", "long", nil, "Un message long"},
+		{"fr", "missing {name}", []interface{}{"name", "foo"}, "missing foo"},
+	}
+	for _, test := range tests {
+		actual := tr.Translate(test.locale, test.key, test.args...)
+		if actual != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+}
+
+func TestTranslatorTranslatePlural(t *testing.T) {
+	tr := newTestTranslator(t)
+	tests := []struct {
+		locale   string
+		n        int
+		expected string
+	}{
+		{"en", 0, "0 apples"},
+		{"en", 1, "1 apple"},
+		{"en", 2, "2 apples"},
+		{"fr", 0, "0 pomme"},
+		{"fr", 1, "1 pomme"},
+		{"fr", 2, "2 pommes"},
+	}
+	for _, test := range tests {
+		actual := tr.TranslatePlural(test.locale, "apples", test.n)
+		if actual != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+
+	tr.SetPluralRule("fr", pluralRuleOneForm)
+	if actual := tr.TranslatePlural("fr", "apples", 2); actual != "2 pomme" {
+		t.Errorf("expected custom plural rule to be applied, got %q", actual)
+	}
+}
+
+func TestPluralRules(t *testing.T) {
+	tests := []struct {
+		locale   string
+		n        int
+		expected int
+	}{
+		{"ru", 1, 0},
+		{"ru", 21, 0},
+		{"ru", 3, 1},
+		{"ru", 11, 2},
+		{"pl", 1, 0},
+		{"pl", 22, 1},
+		{"pl", 25, 2},
+		{"cs", 3, 1},
+		{"ja", 5, 0},
+	}
+	for _, test := range tests {
+		if actual := pluralRules[test.locale](test.n); actual != test.expected {
+			t.Errorf("%s: expected plural form %d of %d, got %d", test.locale, test.expected, test.n, actual)
+		}
+	}
+}
+
+func TestParsePO(t *testing.T) {
+	tests := []string{
+		`"orphan"`,
+		`msgid "foo`,
+		`msgid`,
+		`unknown "foo"`,
+		"msgid \"foo\"\nmsgstr[1] \"bar\"",
+	}
+	for _, content := range tests {
+		if _, err := parsePO([]byte(content)); err == nil {
+			t.Errorf("expected an error for %q, got nil", content)
+		}
+	}
+}
+
+func TestI18n(t *testing.T) {
+	m := New(testViewsFileSystem, I18n(newTestTranslator(t)))
+	data := map[string]interface{}{"name": "foo", "count": 2}
+	tests := []struct {
+		ctx      context.Context
+		expected string
+	}{
+		{context.Background(), "<p>en: Hello foo, 2 apples</p>"},
+		{WithLocale(context.Background(), "fr"), "<p>fr: Bonjour foo, 2 pommes</p>"},
+	}
+	for _, test := range tests {
+		w := bytes.NewBuffer(nil)
+		if err := m.RenderPartialContext(test.ctx, w, "i18n", data); err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		if actual := string(bytes.TrimSpace(w.Bytes())); actual != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+}
+
+func TestI18nConcurrent(t *testing.T) {
+	// the bound templates are reused, so that the concurrent renders must not
+	// share the context.
+	m := New(testViewsFileSystem, I18n(newTestTranslator(t)))
+	data := map[string]interface{}{"name": "foo", "count": 2}
+	expected := map[string]string{
+		"en": "<p>en: Hello foo, 2 apples</p>",
+		"fr": "<p>fr: Bonjour foo, 2 pommes</p>",
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		for locale := range expected {
+			wg.Add(1)
+			go func(locale string) {
+				defer wg.Done()
+				w := bytes.NewBuffer(nil)
+				if err := m.RenderPartialContext(WithLocale(context.Background(), locale), w, "i18n", data); err != nil {
+					t.Errorf("failed to render: %s", err)
+					return
+				}
+				if actual := string(bytes.TrimSpace(w.Bytes())); actual != expected[locale] {
+					t.Errorf("expected %q, got %q", expected[locale], actual)
+				}
+			}(locale)
+		}
+	}
+	wg.Wait()
+}
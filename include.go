@@ -0,0 +1,70 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// DefaultIncludeDepth is the default max depth of nested includes.
+const DefaultIncludeDepth = 10
+
+// Include is an option that registers the include function, which renders
+// another view without layout inline, the view is compiled and cached by the
+// manager.
+//
+//	{{ include "user/card" .user }}
+//
+// It fails on cyclic includes, or the nesting depth exceeds maxDepth, defaults
+// to DefaultIncludeDepth if non-positive.
+func Include(maxDepth int) Option {
+	if maxDepth <= 0 {
+		maxDepth = DefaultIncludeDepth
+	}
+	return func(m *Manager) {
+		m.addContextFunc("include", func(ctx context.Context) interface{} {
+			return func(view string, data ...interface{}) (template.HTML, error) {
+				return m.include(ctx, maxDepth, view, data)
+			}
+		})
+	}
+}
+
+type includeStackKey struct{}
+
+func (m *Manager) include(ctx context.Context, maxDepth int, view string, data []interface{}) (template.HTML, error) {
+	if len(data) > 1 {
+		return "", fmt.Errorf("include %q: too many arguments", view)
+	}
+	stack, _ := ctx.Value(includeStackKey{}).([]string)
+	if stack == nil {
+		if state := renderStateFromContext(ctx); state != nil {
+			stack = []string{state.view}
+		}
+	}
+	for _, v := range stack {
+		if v == view {
+			return "", fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), view)
+		}
+	}
+	if len(stack) > maxDepth {
+		return "", fmt.Errorf("include %q: exceeded max depth %d", view, maxDepth)
+	}
+	stack = append(stack[:len(stack):len(stack)], view)
+
+	var d interface{}
+	if len(data) > 0 {
+		d = data[0]
+	}
+	buf := bytes.NewBuffer(nil)
+	if err := m.execute(context.WithValue(ctx, includeStackKey{}, stack), buf, "", view, d); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"net/http"
+	"path"
+	"testing"
+)
+
+var testIncludeFileSystem = http.Dir(path.Join("testdata", "include"))
+
+func TestInclude(t *testing.T) {
+	m := New(testIncludeFileSystem, Include(0))
+	w := bytes.NewBuffer(nil)
+	data := map[string]interface{}{
+		"users": []map[string]interface{}{{"name": "foo"}, {"name": "<bar>"}},
+	}
+	if err := m.RenderPartial(w, "page", data); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	if expected := "<ul><li>foo</li><li>&lt;bar&gt;</li></ul>\n"; w.String() != expected {
+		t.Errorf("expected %q, got %q", expected, w.String())
+	}
+
+	w.Reset()
+	if err := m.RenderPartial(w, "one", nil); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	if expected := "123"; w.String() != expected {
+		t.Errorf("expected %q, got %q", expected, w.String())
+	}
+}
+
+func TestIncludeErrors(t *testing.T) {
+	tests := []struct {
+		maxDepth int
+		view     string
+		err      string
+	}{
+		{0, "a", "include cycle: a -> b -> a"},
+		{1, "one", `include "three": exceeded max depth 1`},
+		{0, "args", `include "three": too many arguments`},
+		{0, "missing", "no such file"},
+	}
+	for _, test := range tests {
+		m := New(testIncludeFileSystem, Include(test.maxDepth))
+		err := m.RenderPartial(bytes.NewBuffer(nil), test.view, nil)
+		if err == nil || !bytes.Contains([]byte(err.Error()), []byte(test.err)) {
+			t.Errorf("%s: expected error %q, got %v", test.view, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+// Logger is a structured logger, the args are key-value pairs, *slog.Logger
+// satisfies this interface.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Logging sets the logger, it logs cache misses and compilations at debug
+// level, reloads at info level, and render errors, including the compile
+// errors, at error level once.
+//
+//	views.New(fs, views.Logging(slog.Default()))
+func Logging(logger Logger) Option {
+	return func(m *Manager) {
+		m.logger = logger
+	}
+}
+
+// Reload clears the cached templates and view files, so that the templates
+// will be resolved and recompiled on next rendering.
+func (m *Manager) Reload() {
+	m.mutex.Lock()
+	n := 0
+	for _, templates := range m.templates {
+		n += len(templates)
+	}
+	m.templates = nil
+	m.viewFiles = nil
+	m.mutex.Unlock()
+	if m.logger != nil {
+		m.logger.Info("views: reloaded", "templates", n)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type fakeLogger struct {
+	entries []string
+}
+
+func (l *fakeLogger) log(level, msg string, args []interface{}) {
+	entry := level + " " + msg
+	for i := 0; i+1 < len(args); i += 2 {
+		if args[i] == "duration" {
+			continue
+		}
+		entry += fmt.Sprintf(" %s=%v", args[i], args[i+1])
+	}
+	l.entries = append(l.entries, entry)
+}
+
+func (l *fakeLogger) Debug(msg string, args ...interface{}) {
+	l.log("DEBUG", msg, args)
+}
+
+func (l *fakeLogger) Info(msg string, args ...interface{}) {
+	l.log("INFO", msg, args)
+}
+
+func (l *fakeLogger) Warn(msg string, args ...interface{}) {
+	l.log("WARN", msg, args)
+}
+
+func (l *fakeLogger) Error(msg string, args ...interface{}) {
+	l.log("ERROR", msg, args)
+}
+
+func TestLogging(t *testing.T) {
+	logger := &fakeLogger{}
+	m := New(testViewsFileSystem, Logging(logger))
+	m.RenderPartial(bytes.NewBuffer(nil), "shared", nil)
+	m.RenderPartial(bytes.NewBuffer(nil), "shared", nil)
+	m.Reload()
+	m.RenderPartial(bytes.NewBuffer(nil), "shared", nil)
+	m.RenderLayout(bytes.NewBuffer(nil), "nonexistent", "shared", nil)
+
+	expected := []string{
+		"DEBUG views: cache miss layout= view=shared",
+		"DEBUG views: compiled layout= view=shared files=[/shared.tmpl]",
+		"INFO views: reloaded templates=1",
+		"DEBUG views: cache miss layout= view=shared",
+		"DEBUG views: compiled layout= view=shared files=[/shared.tmpl]",
+		"DEBUG views: cache miss layout=nonexistent view=shared",
+		`ERROR views: failed to render layout=nonexistent view=shared error=no such layout "nonexistent"`,
+	}
+	if !reflect.DeepEqual(logger.entries, expected) {
+		t.Errorf("expected log entries %q, got %q", expected, logger.entries)
+	}
+
+	logger.entries = nil
+	m.RenderPartial(bytes.NewBuffer(nil), "nonexistent", nil)
+	errors := 0
+	for _, entry := range logger.entries {
+		if strings.HasPrefix(entry, "ERROR ") {
+			errors++
+		}
+	}
+	if len(logger.entries) != 3 || !strings.HasPrefix(logger.entries[1], "DEBUG views: failed to compile") || errors != 1 {
+		t.Errorf("expected compile error to be logged once, got %q", logger.entries)
+	}
+}
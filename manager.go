@@ -5,14 +5,18 @@
 package views
 
 import (
+	"context"
 	"fmt"
 	"html/template"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"os"
 	"path"
+	"reflect"
 	"strings"
 	"sync"
+	"time"
 )
 
 type layout struct {
@@ -22,38 +26,105 @@ type layout struct {
 
 // Manager is the views manager.
 type Manager struct {
-	fs            http.FileSystem
-	path          string
-	defaultLayout string
-	layouts       map[string]*layout
-	layoutsDir    string
-	partialsDir   string
-	suffix        string
-	delims        []string
-	funcMap       template.FuncMap
-	cache         bool
-	mutex         *sync.Mutex
-	templates     map[string]map[string]*template.Template
+	fs              http.FileSystem
+	path            string
+	defaultLayout   string
+	layouts         map[string]*layout
+	layoutsDir      string
+	partialsDir     string
+	suffix          string
+	delims          []string
+	funcMap         template.FuncMap
+	helpers         template.FuncMap
+	cache           bool
+	mutex           *sync.Mutex
+	engine          Engine
+	markdown        MarkdownConverter
+	templates       map[string]map[string]*viewTemplate
+	viewFiles       map[string]viewFile
+	contextFuncs    map[string]contextFunc
+	composers       []*composer
+	beforeHooks     []Hook
+	afterHooks      []Hook
+	collector       Collector
+	tracer          Tracer
+	logger          Logger
+	lastModified    bool
+	minify          bool
+	compress        bool
+	compressMinSize int
+	encodings       []encoding
+	cspPolicy       string
+	componentsDir   string
+	flashStore      FlashStore
+	sharedMutex     *sync.RWMutex
+	shared          map[string]interface{}
 }
 
+// viewTemplate is the compiled template of a view with particular layout.
+type viewTemplate struct {
+	Template
+	files []string
+	// data is the data of front matter.
+	data map[string]interface{}
+	// modTime is the latest modification time of files.
+	modTime time.Time
+	// components is the props schemas of components, the nil schema means
+	// that the props are not validated.
+	components map[string]map[string]Prop
+	// bound is the pool of bound templates.
+	bound sync.Pool
+}
+
+// viewFile is the resolved file of a view.
+type viewFile struct {
+	filename string
+	// layout is the layout declared in the front matter of Markdown view,
+	// hasLayout reports whether it is declared.
+	layout    string
+	hasLayout bool
+}
+
+// contextFunc returns a template function bound to the given render context.
+type contextFunc func(ctx context.Context) interface{}
+
 // New returns a manager with the given filesystem and options.
 func New(fs http.FileSystem, opts ...Option) *Manager {
+	m := newManager(fs)
+	m.engine = htmlEngine{m.delims}
+	m.applyOptions(opts)
+	return m
+}
+
+// NewText returns a manager that bases on text/template, which is suitable
+// for generating plain text, such as email bodies, CSV files etc.
+func NewText(fs http.FileSystem, opts ...Option) *Manager {
+	m := newManager(fs)
+	m.engine = textEngine{m.delims}
+	m.applyOptions(opts)
+	return m
+}
+
+func newManager(fs http.FileSystem) *Manager {
 	m := &Manager{
 		fs:            fs,
 		suffix:        ".tmpl",
 		delims:        []string{"{{", "}}"},
 		mutex:         &sync.Mutex{},
+		sharedMutex:   &sync.RWMutex{},
 		defaultLayout: "main",
 		layoutsDir:    "layouts",
 		partialsDir:   "partials",
 		cache:         true,
 	}
+	m.AddFunc("global", m.Shared)
+	return m
+}
 
+func (m *Manager) applyOptions(opts []Option) {
 	for _, opt := range opts {
 		opt(m)
 	}
-
-	return m
 }
 
 // AddLayout adds a layout with the given name and partials.
@@ -72,87 +143,280 @@ func (m *Manager) AddFunc(name string, f interface{}) {
 	m.funcMap[name] = f
 }
 
+func (m *Manager) addContextFunc(name string, f contextFunc) {
+	if m.contextFuncs == nil {
+		m.contextFuncs = make(map[string]contextFunc)
+	}
+	m.contextFuncs[name] = f
+}
+
 // Render renders a view with default layout.
 func (m *Manager) Render(w io.Writer, view string, data interface{}) error {
-	return m.RenderLayout(w, m.defaultLayout, view, data)
+	return m.RenderContext(context.Background(), w, view, data)
 }
 
 // RenderLayout renders a view with particular layout.
 func (m *Manager) RenderLayout(w io.Writer, layout, view string, data interface{}) error {
-	return m.render(w, layout, view, data)
+	return m.RenderLayoutContext(context.Background(), w, layout, view, data)
 }
 
 // RenderPartial renders a view without layout.
 func (m *Manager) RenderPartial(w io.Writer, view string, data interface{}) error {
-	return m.render(w, "", view, data)
+	return m.RenderPartialContext(context.Background(), w, view, data)
+}
+
+// RenderContext renders a view with default layout and the given context.
+func (m *Manager) RenderContext(ctx context.Context, w io.Writer, view string, data interface{}) error {
+	return m.RenderLayoutContext(ctx, w, m.viewLayout(view), view, data)
 }
 
-func (m *Manager) getTemplate(layout, view string) (*template.Template, error) {
-	if v, ok := m.templates[layout][view]; ok {
-		return v, nil
+// RenderLayoutContext renders a view with particular layout and the given context.
+func (m *Manager) RenderLayoutContext(ctx context.Context, w io.Writer, layout, view string, data interface{}) error {
+	return m.render(ctx, w, layout, view, data)
+}
+
+// RenderPartialContext renders a view without layout with the given context.
+func (m *Manager) RenderPartialContext(ctx context.Context, w io.Writer, view string, data interface{}) error {
+	return m.render(ctx, w, "", view, data)
+}
+
+// viewLayout returns the default layout of the view, the layout declared in
+// the front matter of Markdown views takes precedence.
+func (m *Manager) viewLayout(view string) string {
+	if m.markdown == nil {
+		return m.defaultLayout
+	}
+	if f := m.lookupViewFile(view, true); f.hasLayout {
+		return f.layout
 	}
+	return m.defaultLayout
+}
 
-	files := []string{}
-	if layout != "" {
-		l, ok := m.layouts[layout]
-		if !ok {
-			return nil, fmt.Errorf("no such layout %q", layout)
-		}
-		files = append(files, m.findLayoutFile(l.name))
-		for _, partial := range l.partials {
-			files = append(files, m.findPartialFile(partial))
-		}
+func (m *Manager) getTemplate(layout, view string) (*viewTemplate, error) {
+	v, _, err := m.loadTemplate(context.Background(), layout, view)
+	return v, err
+}
+
+// loadTemplate returns the compiled template and whether it is retrieved from
+// cache.
+func (m *Manager) loadTemplate(ctx context.Context, layout, view string) (*viewTemplate, bool, error) {
+	var (
+		v  *viewTemplate
+		ok bool
+	)
+	if m.cache {
+		m.mutex.Lock()
+		v, ok = m.templates[layout][view]
+		m.mutex.Unlock()
+	}
+	if m.collector != nil {
+		m.collector.ObserveCache(layout, view, ok)
+	}
+	if ok {
+		return v, true, nil
+	}
+	if m.logger != nil && m.cache {
+		m.logger.Debug("views: cache miss", "layout", layout, "view", view)
 	}
-	files = append(files, m.findViewFile(view))
 
-	v, err := m.newTemplate(files)
+	files, err := m.templateFiles(layout, view)
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+	_, span := m.startSpan(ctx, "views.compile", layout, view)
+	start := time.Now()
+	v, err = m.newTemplate(files)
+	duration := time.Since(start)
+	if m.collector != nil {
+		m.collector.ObserveCompile(layout, view, duration, err)
+	}
+	if m.logger != nil {
+		if err != nil {
+			// the error is logged by render, only the files are logged here.
+			m.logger.Debug("views: failed to compile", "layout", layout, "view", view, "files", files, "error", err)
+		} else {
+			m.logger.Debug("views: compiled", "layout", layout, "view", view, "files", files, "duration", duration)
+		}
+	}
+	span.SetAttribute("views.files", strings.Join(files, ","))
+	span.end(err)
+	if err != nil {
+		return nil, false, err
 	}
 
 	if m.cache {
 		m.mutex.Lock()
 		defer m.mutex.Unlock()
 		if m.templates == nil {
-			m.templates = make(map[string]map[string]*template.Template)
+			m.templates = make(map[string]map[string]*viewTemplate)
 		}
 		if _, ok := m.templates[layout]; !ok {
-			m.templates[layout] = make(map[string]*template.Template)
+			m.templates[layout] = make(map[string]*viewTemplate)
 		}
 		m.templates[layout][view] = v
 	}
 
-	return v, nil
+	return v, false, nil
 }
 
-func (m *Manager) newTemplate(files []string) (*template.Template, error) {
-	tmpl := template.New(path.Base(files[0])).
-		Funcs(m.funcMap).
-		Delims(m.delims[0], m.delims[1])
+// templateFiles returns the files of layout, partials and view.
+func (m *Manager) templateFiles(layout, view string) ([]string, error) {
+	files := []string{}
+	if layout != "" {
+		l, ok := m.layouts[layout]
+		if !ok {
+			return nil, fmt.Errorf("no such layout %q", layout)
+		}
+		files = append(files, m.findLayoutFile(l.name))
+		for _, partial := range l.partials {
+			files = append(files, m.findPartialFile(partial))
+		}
+	}
+	return append(files, m.resolveViewFile(view)), nil
+}
 
-	for _, filename := range files {
-		file, err := m.fs.Open(filename)
+func (m *Manager) newTemplate(files []string) (*viewTemplate, error) {
+	v := &viewTemplate{files: files}
+	sources := make([]Source, len(files))
+	for i, filename := range files {
+		content, err := m.readSource(v, filename)
 		if err != nil {
 			return nil, err
 		}
-		content, err := ioutil.ReadAll(file)
-		if err != nil {
-			return nil, err
+		if m.markdown != nil && i == len(files)-1 && strings.HasSuffix(filename, markdownSuffix) {
+			if content, v.data, err = m.markdownSource(content, len(files) > 1); err != nil {
+				return nil, fmt.Errorf("failed to parse %q: %s", filename, err)
+			}
 		}
-		defer file.Close()
-		tmpl, err = tmpl.Parse(string(content))
+		sources[i] = Source{Filename: filename, Content: m.minifySource(content)}
+	}
+	if m.componentsDir != "" {
+		components, err := m.componentSources(v)
 		if err != nil {
 			return nil, err
 		}
+		sources = append(sources, components...)
+	}
+
+	funcs := make(map[string]interface{}, len(m.helpers)+len(m.funcMap)+len(m.contextFuncs))
+	for name, f := range m.helpers {
+		funcs[name] = f
+	}
+	for name, f := range m.funcMap {
+		funcs[name] = f
+	}
+	for name, f := range m.bindContextFuncs(context.Background()) {
+		funcs[name] = f
+	}
+
+	tmpl, err := m.engine.Parse(sources, funcs)
+	if err != nil {
+		return nil, err
+	}
+	v.Template = tmpl
+	return v, nil
+}
+
+// readSource reads the file, and updates the modification time of template.
+func (m *Manager) readSource(v *viewTemplate, filename string) ([]byte, error) {
+	content, info, err := readFileInfo(m.fs, filename)
+	if err != nil {
+		return nil, err
+	}
+	if info.ModTime().After(v.modTime) {
+		v.modTime = info.ModTime()
+	}
+	return content, nil
+}
+
+func (m *Manager) minifySource(content []byte) []byte {
+	if _, ok := m.engine.(htmlEngine); ok && m.minify {
+		return minifyHTML(content, m.delims[0], m.delims[1])
 	}
+	return content
+}
+
+func readFile(fs http.FileSystem, filename string) ([]byte, error) {
+	content, _, err := readFileInfo(fs, filename)
+	return content, err
+}
 
-	return tmpl, nil
+func readFileInfo(fs http.FileSystem, filename string) ([]byte, os.FileInfo, error) {
+	file, err := fs.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := ioutil.ReadAll(file)
+	return content, info, err
 }
 
 func (m *Manager) findViewFile(view string) string {
 	return m.absFilepath(m.getFileName(view))
 }
 
+// resolveViewFile returns the template file of the view, falls back to the
+// Markdown file if Markdown is enabled and the template file does not exist.
+func (m *Manager) resolveViewFile(view string) string {
+	if m.markdown == nil {
+		return m.findViewFile(view)
+	}
+	return m.lookupViewFile(view, false).filename
+}
+
+// lookupViewFile resolves the file of the view, the layout declared in the
+// front matter is parsed if layout is true. The result is cached if cache is
+// enabled, so that the files are not opened on every rendering.
+func (m *Manager) lookupViewFile(view string, layout bool) viewFile {
+	if m.cache {
+		m.mutex.Lock()
+		f, ok := m.viewFiles[view]
+		m.mutex.Unlock()
+		if ok {
+			return f
+		}
+	}
+
+	f := viewFile{filename: m.findViewFile(view)}
+	if file, err := m.fs.Open(f.filename); err == nil {
+		file.Close()
+	} else {
+		f.filename = m.absFilepath(view + markdownSuffix)
+		if layout || m.cache {
+			f.layout, f.hasLayout = m.frontMatterLayout(f.filename)
+		}
+	}
+
+	if m.cache {
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		if m.viewFiles == nil {
+			m.viewFiles = make(map[string]viewFile)
+		}
+		m.viewFiles[view] = f
+	}
+	return f
+}
+
+// frontMatterLayout returns the layout declared in the front matter of the
+// given Markdown file.
+func (m *Manager) frontMatterLayout(filename string) (string, bool) {
+	content, err := readFile(m.fs, filename)
+	if err != nil {
+		return "", false
+	}
+	data, _, err := parseFrontMatter(content)
+	if err != nil {
+		return "", false
+	}
+	layout, ok := data["layout"].(string)
+	return layout, ok
+}
+
 func (m *Manager) findLayoutFile(layout string) string {
 	return m.absFilepath(path.Join(m.layoutsDir, m.getFileName(layout)))
 }
@@ -169,11 +433,136 @@ func (m *Manager) getFileName(view string) string {
 	return view + m.suffix
 }
 
-func (m *Manager) render(w io.Writer, layout, view string, data interface{}) error {
-	v, err := m.getTemplate(layout, view)
+func (m *Manager) bindContextFuncs(ctx context.Context) map[string]interface{} {
+	funcs := map[string]interface{}{}
+	for name, f := range m.contextFuncs {
+		funcs[name] = f(ctx)
+	}
+	return funcs
+}
+
+func (m *Manager) render(ctx context.Context, w io.Writer, layout, view string, data interface{}) error {
+	var err error
+	switch {
+	case m.collector != nil:
+		err = m.renderWithMetrics(ctx, w, layout, view, data)
+	case len(m.beforeHooks) == 0 && len(m.afterHooks) == 0:
+		err = m.execute(ctx, w, layout, view, data)
+	default:
+		err = m.renderWithHooks(ctx, w, layout, view, data)
+	}
+	if err != nil && m.logger != nil {
+		m.logger.Error("views: failed to render", "layout", layout, "view", view, "error", err)
+	}
+	return err
+}
+
+func (m *Manager) execute(ctx context.Context, w io.Writer, layout, view string, data interface{}) (err error) {
+	ctx, span := m.startSpan(ctx, "views.execute", layout, view)
+	if m.tracer != nil {
+		cw := &countWriter{w: w}
+		w = cw
+		defer func() {
+			span.SetAttribute("views.output_size", cw.n)
+			span.end(err)
+		}()
+	}
+
+	v, hit, err := m.loadTemplate(ctx, layout, view)
+	span.SetAttribute("views.cache_hit", hit)
+	if err != nil {
+		return err
+	}
+	// the included views do not override the result of the outermost view.
+	if result, ok := ctx.Value(httpResultKey{}).(*httpResult); ok && result.modTime.IsZero() {
+		result.modTime = v.modTime
+	}
+
+	if data, err = m.compose(ctx, layout, view, data); err != nil {
+		return err
+	}
+	if v.data != nil {
+		if data, err = mergeData(data, v.data); err != nil {
+			return err
+		}
+	}
+
+	if len(m.contextFuncs) == 0 {
+		return v.Execute(w, data)
+	}
+
+	if m.cspPolicy != "" {
+		if ctx, _, err = withNonce(ctx); err != nil {
+			return err
+		}
+	}
+	// the cached template is never executed, so that it can be cloned and
+	// bound with the functions of current context, the clones are reused.
+	b, err := m.boundTemplate(v)
 	if err != nil {
 		return err
 	}
+	defer v.bound.Put(b)
+	state := &renderState{layout: layout, view: view, tmpl: b.Template, components: v.components}
+	b.ctx = context.WithValue(ctx, renderStateKey{}, state)
+	defer func() {
+		b.ctx = nil
+	}()
+	return b.Execute(w, data)
+}
+
+// boundTemplate is a clone of template, whose context functions are bound to
+// the context of current render.
+type boundTemplate struct {
+	Template
+	ctx context.Context
+}
+
+// boundTemplate returns a bound template from the pool of template, or
+// creates a new one.
+func (m *Manager) boundTemplate(v *viewTemplate) (*boundTemplate, error) {
+	if b, ok := v.bound.Get().(*boundTemplate); ok {
+		return b, nil
+	}
+	b := &boundTemplate{}
+	funcs := make(map[string]interface{}, len(m.contextFuncs))
+	for name, f := range m.contextFuncs {
+		funcs[name] = bindFunc(b, f)
+	}
+	tmpl, err := v.Template.Bind(funcs)
+	if err != nil {
+		return nil, err
+	}
+	b.Template = tmpl
+	return b, nil
+}
+
+// bindFunc returns a function that calls the function bound to the context of
+// the bound template.
+func bindFunc(b *boundTemplate, f contextFunc) interface{} {
+	typ := reflect.TypeOf(f(context.Background()))
+	return reflect.MakeFunc(typ, func(args []reflect.Value) []reflect.Value {
+		fn := reflect.ValueOf(f(b.ctx))
+		if typ.IsVariadic() {
+			return fn.CallSlice(args)
+		}
+		return fn.Call(args)
+	}).Interface()
+}
+
+// renderState is the state of current rendering, which is available to the
+// context functions.
+type renderState struct {
+	layout string
+	view   string
+	tmpl   Template
+	// components is the components of current template.
+	components map[string]map[string]Prop
+}
+
+type renderStateKey struct{}
 
-	return v.Execute(w, data)
+func renderStateFromContext(ctx context.Context) *renderState {
+	state, _ := ctx.Value(renderStateKey{}).(*renderState)
+	return state
 }
@@ -18,14 +18,18 @@ import (
 )
 
 var (
-	testManager      *Manager
-	testCacheManager *Manager
-	testFileSystem   http.FileSystem
+	testManager         *Manager
+	testCacheManager    *Manager
+	testFileSystem      http.FileSystem
+	testDataFileSystem  http.FileSystem
+	testViewsFileSystem http.FileSystem
 )
 
 func TestMain(m *testing.M) {
 	_, filename, _, _ := runtime.Caller(0)
 	testFileSystem = http.Dir(path.Join(path.Dir(filename), "example", "views"))
+	testDataFileSystem = http.Dir(path.Join(path.Dir(filename), "testdata"))
+	testViewsFileSystem = http.Dir(path.Join(path.Dir(filename), "testdata", "views"))
 	testManager = New(
 		testFileSystem,
 		FuncMap(template.FuncMap{
@@ -174,6 +178,20 @@ func BenchmarkManagerRenderPartialCache(b *testing.B) {
 	}
 }
 
+func BenchmarkManagerRenderContextFuncs(b *testing.B) {
+	m := New(testFileSystem, FuncMap(template.FuncMap{"title": strings.Title}), I18n(NewTranslator("en")))
+	m.AddLayout("main", "head", "header", "footer")
+	data := map[string]interface{}{
+		"title": "home",
+	}
+	w := bytes.NewBuffer(nil)
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		m.Render(w, "site/index", data)
+		w.Reset()
+	}
+}
+
 func TestManagerAddFuncMap(t *testing.T) {
 	m := &Manager{}
 	m.AddFunc("title", strings.Title)
@@ -185,3 +203,17 @@ func TestManagerAddFuncMap(t *testing.T) {
 		}
 	}
 }
+
+func TestNewText(t *testing.T) {
+	m := NewText(testFileSystem)
+	if _, ok := m.engine.(textEngine); !ok {
+		t.Fatalf("expected text engine, got %T", m.engine)
+	}
+	w := bytes.NewBuffer(nil)
+	if err := m.RenderPartial(w, "user/login", nil); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	if _, ok := m.templates[""]["user/login"].Template.(textTemplate); !ok {
+		t.Errorf("expected text template, got %T", m.templates[""]["user/login"].Template)
+	}
+}
@@ -0,0 +1,313 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const markdownSuffix = ".md"
+
+// MarkdownConverter converts Markdown to HTML, the output is trusted and
+// will not be escaped, so that it should be sanitized by the converter.
+type MarkdownConverter func(source []byte) ([]byte, error)
+
+// Markdown enables Markdown views, a view falls back to the ".md" file if the
+// template file does not exist. The front matter (YAML or TOML) is merged into
+// the data, and the "layout" in front matter takes precedence over the default
+// layout. The body is converted to HTML and exposed as the "content" block.
+//
+//	---
+//	title: Getting Started
+//	layout: docs
+//	---
+//	# Getting Started
+//
+// A simple built-in converter that escapes raw HTML is used if converter is nil.
+// Only the flat YAML front matter is supported out of box, the others formats
+// require to register the unmarshal functions, see RegisterFormat.
+func Markdown(converter MarkdownConverter) Option {
+	return func(m *Manager) {
+		if converter == nil {
+			converter = convertMarkdown
+		}
+		m.markdown = converter
+	}
+}
+
+// markdownSource converts the Markdown file to template source and front matter.
+func (m *Manager) markdownSource(content []byte, withLayout bool) ([]byte, map[string]interface{}, error) {
+	data, body, err := parseFrontMatter(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	output, err := m.markdown(body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// escapes the left delimiter, so that the output is treated as text.
+	left, right := m.delims[0], m.delims[1]
+	output = bytes.Replace(output, []byte(left), []byte(left+strconv.Quote(left)+right), -1)
+	if withLayout {
+		output = append([]byte(left+` define "content" `+right), output...)
+		output = append(output, []byte(left+" end "+right)...)
+	}
+	return output, data, nil
+}
+
+func parseFrontMatter(content []byte) (map[string]interface{}, []byte, error) {
+	content = bytes.Replace(content, []byte("\r\n"), []byte("\n"), -1)
+	var ext, delim string
+	switch {
+	case bytes.HasPrefix(content, []byte("---\n")):
+		ext, delim = ".yaml", "---"
+	case bytes.HasPrefix(content, []byte("+++\n")):
+		ext, delim = ".toml", "+++"
+	default:
+		return nil, content, nil
+	}
+
+	rest := content[len(delim)+1:]
+	end := bytes.Index(rest, []byte("\n"+delim+"\n"))
+	var frontMatter, body []byte
+	switch {
+	case end >= 0:
+		frontMatter, body = rest[:end], rest[end+len(delim)+2:]
+	case bytes.HasSuffix(rest, []byte("\n"+delim)):
+		frontMatter = rest[:len(rest)-len(delim)-1]
+	default:
+		return nil, nil, fmt.Errorf("unclosed front matter")
+	}
+
+	data := map[string]interface{}{}
+	if _, ok := lookupFormat(ext); ok || ext != ".yaml" {
+		if err := unmarshalFile(ext, frontMatter, &data); err != nil {
+			return nil, nil, fmt.Errorf("invalid front matter: %s", err)
+		}
+		return data, body, nil
+	}
+
+	for i, line := range strings.Split(string(frontMatter), "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, nil, fmt.Errorf("invalid front matter: line %d", i+1)
+		}
+		data[strings.TrimSpace(parts[0])] = parseScalar(strings.TrimSpace(parts[1]))
+	}
+	return data, body, nil
+}
+
+func parseScalar(s string) interface{} {
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"') {
+		if v, err := strconv.Unquote(s); err == nil {
+			return v
+		}
+	}
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1)
+	}
+	if v, err := strconv.ParseBool(s); err == nil && (s == "true" || s == "false") {
+		return v
+	}
+	if v, err := strconv.Atoi(s); err == nil {
+		return v
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v
+	}
+	return s
+}
+
+var (
+	markdownHeading     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	markdownRule        = regexp.MustCompile(`^ {0,3}([-*_])( *[-*_]){2,} *$`)
+	markdownListItem    = regexp.MustCompile(`^ {0,3}([-*+]|\d+[.)])\s+(.*)$`)
+	markdownOrderedItem = regexp.MustCompile(`^\d`)
+)
+
+// convertMarkdown is a simple Markdown converter, it supports headings,
+// paragraphs, fenced code blocks, blockquotes, lists, horizontal rules and
+// inline elements such as emphasis, code spans, links and images. Raw HTML is
+// escaped.
+func convertMarkdown(source []byte) ([]byte, error) {
+	lines := strings.Split(strings.Replace(string(source), "\r\n", "\n", -1), "\n")
+	out := &bytes.Buffer{}
+	convertMarkdownBlocks(out, lines)
+	return out.Bytes(), nil
+}
+
+func convertMarkdownBlocks(out *bytes.Buffer, lines []string) {
+	paragraph := []string{}
+	flush := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + convertMarkdownInline(strings.Join(paragraph, "\n")) + "</p>\n")
+			paragraph = paragraph[:0]
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			lang := strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			code := []string{}
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			if lang != "" {
+				out.WriteString(`<pre><code class="language-` + html.EscapeString(lang) + `">`)
+			} else {
+				out.WriteString("<pre><code>")
+			}
+			out.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			if len(code) > 0 {
+				out.WriteString("\n")
+			}
+			out.WriteString("</code></pre>\n")
+		case markdownHeading.MatchString(trimmed):
+			flush()
+			matches := markdownHeading.FindStringSubmatch(trimmed)
+			level := strconv.Itoa(len(matches[1]))
+			out.WriteString("<h" + level + ">" + convertMarkdownInline(matches[2]) + "</h" + level + ">\n")
+		case markdownRule.MatchString(line):
+			flush()
+			out.WriteString("<hr>\n")
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			quote := []string{}
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				l := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quote = append(quote, strings.TrimPrefix(l, " "))
+			}
+			i--
+			out.WriteString("<blockquote>\n")
+			convertMarkdownBlocks(out, quote)
+			out.WriteString("</blockquote>\n")
+		case markdownListItem.MatchString(line):
+			flush()
+			tag := "ul"
+			if markdownOrderedItem.MatchString(trimmed) {
+				tag = "ol"
+			}
+			out.WriteString("<" + tag + ">\n")
+			for i < len(lines) {
+				matches := markdownListItem.FindStringSubmatch(lines[i])
+				if matches == nil {
+					break
+				}
+				item := []string{matches[2]}
+				// continuation lines.
+				for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "" &&
+					!markdownListItem.MatchString(lines[i]) && strings.HasPrefix(lines[i], " "); i++ {
+					item = append(item, strings.TrimSpace(lines[i]))
+				}
+				out.WriteString("<li>" + convertMarkdownInline(strings.Join(item, "\n")) + "</li>\n")
+			}
+			i--
+			out.WriteString("</" + tag + ">\n")
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+}
+
+const markdownPunctuation = "\\`*_{}[]()#+-.!>|~"
+
+func convertMarkdownInline(s string) string {
+	b := &strings.Builder{}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(markdownPunctuation, s[i+1]) >= 0:
+			b.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+			continue
+		case c == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end >= 0 {
+				b.WriteString("<code>" + html.EscapeString(s[i+1:i+1+end]) + "</code>")
+				i += end + 2
+				continue
+			}
+		case (c == '*' || c == '_') && i+1 < len(s) && s[i+1] == c:
+			delim := s[i : i+2]
+			if end := strings.Index(s[i+2:], delim); end > 0 {
+				b.WriteString("<strong>" + convertMarkdownInline(s[i+2:i+2+end]) + "</strong>")
+				i += end + 4
+				continue
+			}
+		case c == '*' || (c == '_' && (i == 0 || !isWordChar(s[i-1]))):
+			if end := strings.IndexByte(s[i+1:], c); end > 0 {
+				b.WriteString("<em>" + convertMarkdownInline(s[i+1:i+1+end]) + "</em>")
+				i += end + 2
+				continue
+			}
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if text, url, n, ok := parseMarkdownLink(s[i+1:]); ok {
+				b.WriteString(`<img src="` + html.EscapeString(sanitizeURL(url)) + `" alt="` + html.EscapeString(text) + `">`)
+				i += n + 1
+				continue
+			}
+		case c == '[':
+			if text, url, n, ok := parseMarkdownLink(s[i:]); ok {
+				b.WriteString(`<a href="` + html.EscapeString(sanitizeURL(url)) + `">` + convertMarkdownInline(text) + "</a>")
+				i += n
+				continue
+			}
+		}
+		b.WriteString(html.EscapeString(s[i : i+1]))
+		i++
+	}
+	return b.String()
+}
+
+// parseMarkdownLink parses the link such as "[text](url)", and returns the
+// text, url and the length of link.
+func parseMarkdownLink(s string) (string, string, int, bool) {
+	closing := strings.Index(s, "](")
+	if closing < 0 {
+		return "", "", 0, false
+	}
+	end := strings.IndexByte(s[closing+2:], ')')
+	if end < 0 {
+		return "", "", 0, false
+	}
+	url := strings.TrimSpace(s[closing+2 : closing+2+end])
+	if i := strings.IndexByte(url, ' '); i >= 0 {
+		// drops the title.
+		url = url[:i]
+	}
+	return s[1:closing], url, closing + end + 3, true
+}
+
+// sanitizeURL replaces the URL that has unsafe scheme, such as javascript, with "#".
+func sanitizeURL(url string) string {
+	i := strings.IndexAny(url, ":/?#")
+	if i < 0 || url[i] != ':' {
+		return url
+	}
+	switch strings.ToLower(url[:i]) {
+	case "http", "https", "mailto":
+		return url
+	}
+	return "#"
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
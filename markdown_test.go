@@ -0,0 +1,182 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	m := New(testViewsFileSystem, Markdown(nil))
+	m.AddLayout("main")
+	m.AddLayout("docs")
+
+	w := bytes.NewBuffer(nil)
+	if err := m.Render(w, "docs/intro", map[string]interface{}{"name": "foo"}); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	expected := "<title>Getting Started</title><article><h1>Getting Started</h1>\n" +
+		"<p>Hello {{ .name }}, &lt;script&gt;alert(1)&lt;/script&gt;</p>\n</article>"
+	if actual := strings.TrimSpace(w.String()); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	if _, ok := m.templates["docs"]["docs/intro"]; !ok {
+		t.Error("failed to cache the Markdown view")
+	}
+
+	tests := []struct {
+		layout   string
+		view     string
+		expected string
+	}{
+		{"main", "docs/plain", "<main><h2>Plain</h2>\n</main>"},
+		{"", "docs/plain", "<h2>Plain</h2>"},
+		{"main", "content", "<main>  </main>"},
+	}
+	for _, test := range tests {
+		w.Reset()
+		if err := m.RenderLayout(w, test.layout, test.view, nil); err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		if actual := strings.TrimSpace(w.String()); actual != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+
+	if err := m.Render(w, "docs/nonexistent", nil); err == nil {
+		t.Error("expected an error about view file not found, got nil")
+	}
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		content  string
+		data     map[string]interface{}
+		body     string
+		hasError bool
+	}{
+		{"body", nil, "body", false},
+		{"---\ntitle: 'It''s'\ncount: 2\nratio: 0.5\n# comment\n---\nbody", map[string]interface{}{"title": "It's", "count": 2, "ratio": 0.5}, "body", false},
+		{"---\r\npublished: true\r\n---", map[string]interface{}{"published": true}, "", false},
+		{"---\ntitle: foo\n", nil, "", true},
+		{"---\ninvalid\n---\n", nil, "", true},
+		{"+++\ntitle = \"foo\"\n+++\n", nil, "", true},
+	}
+	for _, test := range tests {
+		data, body, err := parseFrontMatter([]byte(test.content))
+		if (err != nil) != test.hasError {
+			t.Errorf("%q: unexpected error %v", test.content, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(data, test.data) || string(body) != test.body {
+			t.Errorf("%q: unexpected result %v %q", test.content, data, body)
+		}
+	}
+}
+
+func TestConvertMarkdown(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"# Title #", "<h1>Title</h1>\n"},
+		{"foo\nbar\n\nbaz", "<p>foo\nbar</p>\n<p>baz</p>\n"},
+		{"**bold** *em* _em_ snake_case_name `<code>`", "<p><strong>bold</strong> <em>em</em> <em>em</em> snake_case_name <code>&lt;code&gt;</code></p>\n"},
+		{`\*literal\*`, "<p>*literal*</p>\n"},
+		{"[link](https://example.com \"title\") ![alt](/a.png)", `<p><a href="https://example.com">link</a> <img src="/a.png" alt="alt"></p>` + "\n"},
+		{"[xss](javascript:alert)", `<p><a href="#">xss</a></p>` + "\n"},
+		{"```go\nfmt.Println(\"<p>\")\n```", `<pre><code class="language-go">fmt.Println(&#34;&lt;p&gt;&#34;)` + "\n</code></pre>\n"},
+		{"> quote\n> **bold**", "<blockquote>\n<p>quote\n<strong>bold</strong></p>\n</blockquote>\n"},
+		{"- foo\n  bar\n- baz", "<ul>\n<li>foo\nbar</li>\n<li>baz</li>\n</ul>\n"},
+		{"1. foo\n2. bar", "<ol>\n<li>foo</li>\n<li>bar</li>\n</ol>\n"},
+		{"---", "<hr>\n"},
+		{"<div>raw</div>", "<p>&lt;div&gt;raw&lt;/div&gt;</p>\n"},
+	}
+	for _, test := range tests {
+		actual, err := convertMarkdown([]byte(test.source))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(actual) != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.source, test.expected, actual)
+		}
+	}
+}
+
+func TestMarkdownCompileOnce(t *testing.T) {
+	collector := &fakeCollector{}
+	m := New(testViewsFileSystem, Markdown(nil), Cache(false), Metrics(collector))
+	m.AddLayout("main")
+	m.AddLayout("docs")
+	for _, view := range []string{"content", "docs/intro"} {
+		collector.events = nil
+		if err := m.Render(bytes.NewBuffer(nil), view, nil); err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		compiles := 0
+		for _, event := range collector.events {
+			if strings.HasPrefix(event, "compile ") {
+				compiles++
+			}
+		}
+		if compiles != 1 {
+			t.Errorf("%s: expected one compile, got events %q", view, collector.events)
+		}
+	}
+}
+
+type countFileSystem struct {
+	http.FileSystem
+	opens map[string]int
+}
+
+func (fs *countFileSystem) Open(name string) (http.File, error) {
+	fs.opens[name]++
+	return fs.FileSystem.Open(name)
+}
+
+func TestMarkdownCacheViewFile(t *testing.T) {
+	tests := []struct {
+		cache    bool
+		expected int
+	}{
+		{true, 1},
+		{false, 6},
+	}
+	for _, test := range tests {
+		fs := &countFileSystem{FileSystem: testViewsFileSystem, opens: map[string]int{}}
+		m := New(fs, Markdown(nil), Cache(test.cache))
+		m.AddLayout("main")
+		m.AddLayout("docs")
+		for i := 0; i < 3; i++ {
+			w := bytes.NewBuffer(nil)
+			if err := m.Render(w, "docs/intro", nil); err != nil {
+				t.Fatalf("failed to render: %s", err)
+			}
+			if !strings.Contains(w.String(), "<article>") {
+				t.Errorf("expected the layout declared in front matter, got %q", w.String())
+			}
+		}
+		if actual := fs.opens["/docs/intro.tmpl"]; actual != test.expected {
+			t.Errorf("cache %t: expected the template file to be opened %d times, got %d", test.cache, test.expected, actual)
+		}
+	}
+
+	fs := &countFileSystem{FileSystem: testViewsFileSystem, opens: map[string]int{}}
+	m := New(fs, Markdown(nil))
+	m.AddLayout("docs")
+	m.Render(bytes.NewBuffer(nil), "docs/intro", nil)
+	m.Reload()
+	if len(m.viewFiles) != 0 {
+		t.Errorf("expected the view files to be cleared, got %v", m.viewFiles)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Collector collects the metrics of rendering and compilation.
+type Collector interface {
+	// ObserveRender is called after rendering a view, written is the number
+	// of bytes written.
+	ObserveRender(layout, view string, duration time.Duration, written int, err error)
+
+	// ObserveCompile is called after compiling the template of a view.
+	ObserveCompile(layout, view string, duration time.Duration, err error)
+
+	// ObserveCache is called when looking up the compiled template of a view
+	// in cache.
+	ObserveCache(layout, view string, hit bool)
+}
+
+// Metrics sets the metrics collector.
+func Metrics(collector Collector) Option {
+	return func(m *Manager) {
+		m.collector = collector
+	}
+}
+
+type countWriter struct {
+	w io.Writer
+	n int
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += n
+	return n, err
+}
+
+func (m *Manager) renderWithMetrics(ctx context.Context, w io.Writer, layout, view string, data interface{}) error {
+	start := time.Now()
+	cw := &countWriter{w: w}
+	var err error
+	if len(m.beforeHooks) == 0 && len(m.afterHooks) == 0 {
+		err = m.execute(ctx, cw, layout, view, data)
+	} else {
+		err = m.renderWithHooks(ctx, cw, layout, view, data)
+	}
+	m.collector.ObserveRender(layout, view, time.Since(start), cw.n, err)
+	return err
+}
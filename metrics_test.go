@@ -0,0 +1,58 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeCollector struct {
+	events []string
+}
+
+func (c *fakeCollector) ObserveRender(layout, view string, duration time.Duration, written int, err error) {
+	c.events = append(c.events, fmt.Sprintf("render %s %s %d %t", layout, view, written, err == nil))
+}
+
+func (c *fakeCollector) ObserveCompile(layout, view string, duration time.Duration, err error) {
+	c.events = append(c.events, fmt.Sprintf("compile %s %s %t", layout, view, err == nil))
+}
+
+func (c *fakeCollector) ObserveCache(layout, view string, hit bool) {
+	c.events = append(c.events, fmt.Sprintf("cache %s %s %t", layout, view, hit))
+}
+
+func TestMetrics(t *testing.T) {
+	collector := &fakeCollector{}
+	m := New(testViewsFileSystem, Metrics(collector))
+	m.Share("appName", "foo")
+	for i := 0; i < 2; i++ {
+		if err := m.RenderPartial(bytes.NewBuffer(nil), "shared", nil); err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+	}
+	m.AfterRender(func(e *RenderEvent) error {
+		return nil
+	})
+	m.RenderPartial(bytes.NewBuffer(nil), "nonexistent", nil)
+
+	expected := []string{
+		"cache  shared false",
+		"compile  shared true",
+		"render  shared 5 true",
+		"cache  shared true",
+		"render  shared 5 true",
+		"cache  nonexistent false",
+		"compile  nonexistent false",
+		"render  nonexistent 0 false",
+	}
+	if !reflect.DeepEqual(collector.events, expected) {
+		t.Errorf("expected events %q, got %q", expected, collector.events)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Minify is an option that minifies the HTML templates at parse time, so that
+// there is no runtime cost. It drops comments except the conditional comments
+// and the ones contain actions, removes the whitespace that contains line
+// breaks next to block-level tags, and collapses the other whitespace into a
+// single space, so that the whitespace between inline elements is kept. The
+// content of pre, textarea, script and style elements, quoted attribute
+// values and actions are left untouched. It only applies to the built-in HTML
+// engine.
+func Minify(enabled bool) Option {
+	return func(m *Manager) {
+		m.minify = enabled
+	}
+}
+
+// minifyPreservedTags are the elements whose content is preserved.
+var minifyPreservedTags = map[string]bool{
+	"pre":      true,
+	"textarea": true,
+	"script":   true,
+	"style":    true,
+}
+
+// minifyBlockTags are the block-level and non-rendered elements, the line
+// breaks next to them are insignificant.
+var minifyBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "base": true,
+	"blockquote": true, "body": true, "caption": true, "col": true,
+	"colgroup": true, "dd": true, "details": true, "dialog": true,
+	"div": true, "dl": true, "dt": true, "fieldset": true,
+	"figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"head": true, "header": true, "hgroup": true, "hr": true, "html": true,
+	"li": true, "link": true, "main": true, "meta": true, "nav": true,
+	"noscript": true, "ol": true, "optgroup": true, "option": true,
+	"p": true, "pre": true, "script": true, "section": true, "style": true,
+	"summary": true, "table": true, "tbody": true, "td": true,
+	"template": true, "tfoot": true, "th": true, "thead": true,
+	"title": true, "tr": true, "ul": true,
+}
+
+// minifyHTML minifies the HTML template source with the given delimiters.
+func minifyHTML(src []byte, left, right string) []byte {
+	out := bytes.NewBuffer(make([]byte, 0, len(src)))
+	// preserve is the closing tag of preserved element, such as "</pre".
+	preserve := ""
+	// blockEnd is the end of the last block-level tag in the output.
+	blockEnd := -1
+	for i := 0; i < len(src); {
+		if n := actionLen(src[i:], left, right); n > 0 {
+			out.Write(src[i : i+n])
+			i += n
+			continue
+		}
+
+		if preserve != "" {
+			if !hasPrefixFold(src[i:], preserve) {
+				out.WriteByte(src[i])
+				i++
+				continue
+			}
+			preserve = ""
+		}
+
+		c := src[i]
+		switch {
+		case bytes.HasPrefix(src[i:], []byte("<!--")):
+			end := bytes.Index(src[i+4:], []byte("-->"))
+			if end < 0 {
+				out.Write(src[i:])
+				return out.Bytes()
+			}
+			comment := src[i : i+4+end+3]
+			if bytes.HasPrefix(comment, []byte("<!--[if")) || bytes.Contains(comment, []byte(left)) {
+				out.Write(comment)
+			}
+			i += len(comment)
+		case c == '<' && i+1 < len(src) && (isLetter(src[i+1]) || src[i+1] == '/'):
+			name := tagName(src[i:])
+			if src[i+1] != '/' && minifyPreservedTags[name] {
+				preserve = "</" + name
+			}
+			i += minifyTag(out, src[i:], left, right)
+			if minifyBlockTags[name] {
+				blockEnd = out.Len()
+			}
+		case isSpace(c):
+			j := i
+			for j < len(src) && isSpace(src[j]) {
+				j++
+			}
+			// the line breaks are dropped at the edges and next to the
+			// block-level tags, such as "</p>\n<p>", but not between inline
+			// elements, such as "</b>\n<i>".
+			drop := bytes.IndexByte(src[i:j], '\n') >= 0 &&
+				(out.Len() == 0 || j == len(src) || out.Len() == blockEnd || minifyBlockTags[tagName(src[j:])])
+			if !drop {
+				out.WriteByte(' ')
+			}
+			i = j
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.Bytes()
+}
+
+// tagName returns the lower-case name of the opening or closing tag at the
+// beginning of src, or an empty string if src does not start with a tag.
+func tagName(src []byte) string {
+	if len(src) < 2 || src[0] != '<' {
+		return ""
+	}
+	start := 1
+	if src[start] == '/' {
+		start++
+	}
+	end := start
+	for end < len(src) && (isLetter(src[end]) || end > start && src[end] >= '0' && src[end] <= '9') {
+		end++
+	}
+	return strings.ToLower(string(src[start:end]))
+}
+
+// minifyTag writes the tag and returns the length of the tag, the whitespace
+// outside of quoted attribute values and actions is collapsed.
+func minifyTag(out *bytes.Buffer, src []byte, left, right string) int {
+	var quote byte
+	for i := 0; i < len(src); {
+		if n := actionLen(src[i:], left, right); n > 0 {
+			out.Write(src[i : i+n])
+			i += n
+			continue
+		}
+		c := src[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			out.WriteByte(c)
+			return i + 1
+		case isSpace(c):
+			j := i
+			for j < len(src) && isSpace(src[j]) {
+				j++
+			}
+			if j < len(src) && src[j] != '>' && !(src[j] == '/' && j+1 < len(src) && src[j+1] == '>') {
+				out.WriteByte(' ')
+			}
+			i = j
+			continue
+		}
+		out.WriteByte(c)
+		i++
+	}
+	return len(src)
+}
+
+// actionLen returns the length of action at the beginning of src, or 0 if src
+// does not start with an action.
+func actionLen(src []byte, left, right string) int {
+	if !bytes.HasPrefix(src, []byte(left)) {
+		return 0
+	}
+	end := bytes.Index(src[len(left):], []byte(right))
+	if end < 0 {
+		return len(src)
+	}
+	return len(left) + end + len(right)
+}
+
+func hasPrefixFold(s []byte, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(string(s[:len(prefix)]), prefix)
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,78 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"net/http"
+	"path"
+	"testing"
+)
+
+func TestMinifyHTML(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"<div>\n  <p>foo</p>\n</div>\n", "<div><p>foo</p></div>"},
+		{"<b>foo</b> <i>bar</i>", "<b>foo</b> <i>bar</i>"},
+		{"<p>\n  foo   bar\n</p>", "<p>foo bar</p>"},
+		{"<p>foo\n  bar</p>", "<p>foo bar</p>"},
+		{"<strong>Hello</strong>\n<em>world</em>", "<strong>Hello</strong> <em>world</em>"},
+		{"<p>\n  <a href=\"/\">foo</a>\n  <span>bar</span>\n</p>", "<p><a href=\"/\">foo</a> <span>bar</span></p>"},
+		{"<ul>\n  <li>foo</li>\n  <li>bar</li>\n</ul>", "<ul><li>foo</li><li>bar</li></ul>"},
+		{"<b>foo</b>\n<div>bar</div>", "<b>foo</b><div>bar</div>"},
+		{"<p>foo</p>\n<!-- comment -->\n<p>bar</p>", "<p>foo</p><p>bar</p>"},
+		{"<!--[if IE]><p>IE</p><![endif]-->", "<!--[if IE]><p>IE</p><![endif]-->"},
+		{"<!-- {{ .foo }} -->", "<!-- {{ .foo }} -->"},
+		{"<!-- unclosed", "<!-- unclosed"},
+		{"<a  href=\"/\"\n   title=\"foo   bar\" >link</a>", "<a href=\"/\" title=\"foo   bar\">link</a>"},
+		{"<br  />", "<br/>"},
+		{"<input value='{{ \"a  >  b\" }}'>", "<input value='{{ \"a  >  b\" }}'>"},
+		{"<div>\n  {{ \"foo   bar\" }}\n</div>", "<div>{{ \"foo   bar\" }}</div>"},
+		{"<b>foo</b>\n{{ .bar }}\n<i>baz</i>", "<b>foo</b> {{ .bar }} <i>baz</i>"},
+		{"{{ if .foo }}\n  <p>foo</p>\n{{ end }}", "{{ if .foo }}<p>foo</p>{{ end }}"},
+		{"<pre>\n  foo\n    bar\n</pre>\n<p>\n</p>", "<pre>\n  foo\n    bar\n</pre><p></p>"},
+		{"<PRE> a  b </Pre> <p> c  d </p>", "<PRE> a  b </Pre> <p> c d </p>"},
+		{"<textarea>\n a  b\n</textarea>", "<textarea>\n a  b\n</textarea>"},
+		{"<script>\n  if (a < b) {}\n</script>", "<script>\n  if (a < b) {}\n</script>"},
+		{"<style>\n  a  { color: red }\n</style>", "<style>\n  a  { color: red }\n</style>"},
+		{"a < b", "a < b"},
+		{"{{ unclosed", "{{ unclosed"},
+		{"<p", "<p"},
+	}
+	for _, test := range tests {
+		if actual := string(minifyHTML([]byte(test.src), "{{", "}}")); actual != test.expected {
+			t.Errorf("minifyHTML(%q): expected %q, got %q", test.src, test.expected, actual)
+		}
+	}
+}
+
+func TestMinify(t *testing.T) {
+	fs := http.Dir(path.Join("testdata", "minify"))
+	data := map[string]interface{}{"class": "home", "name": "foo"}
+	tests := []struct {
+		manager  *Manager
+		expected string
+	}{
+		{
+			New(fs, Minify(true)),
+			"<!DOCTYPE html><html><body class=\"home\" id=\"page\"><p><strong>Hello</strong> <em>foo</em>!</p><pre>\n  keep   this\n    </pre></body></html>",
+		},
+		{
+			NewText(fs, Minify(true)),
+			"<!DOCTYPE html>\n<html>\n  <!-- navigation -->\n  <body class=\"home\"\n        id=\"page\">\n    <p>\n      <strong>Hello</strong>\n      <em>foo</em>!\n    </p>\n    <pre>\n  keep   this\n    </pre>\n  </body>\n</html>\n",
+		},
+	}
+	for _, test := range tests {
+		w := bytes.NewBuffer(nil)
+		if err := test.manager.RenderPartial(w, "page", data); err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+		if actual := w.String(); actual != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+}
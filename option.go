@@ -24,6 +24,14 @@ func Delims(left, right string) Option {
 	}
 }
 
+// TemplateEngine sets the template engine, default to the engine based on
+// html/template.
+func TemplateEngine(engine Engine) Option {
+	return func(m *Manager) {
+		m.engine = engine
+	}
+}
+
 // DefaultLayout sets the default layout.
 func DefaultLayout(name string) Option {
 	return func(m *Manager) {
@@ -60,3 +68,12 @@ func FuncMap(funcMap template.FuncMap) Option {
 		}
 	}
 }
+
+// Share shares the given values with all templates, see Manager.Share.
+func Share(values map[string]interface{}) Option {
+	return func(m *Manager) {
+		for key, value := range values {
+			m.Share(key, value)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+// Package otelviews provides the OpenTelemetry adapter of views.Tracer.
+//
+//	tracer := otelviews.NewTracer(otel.Tracer("views"))
+//	manager := views.New(fs, views.Tracing(tracer))
+package otelviews
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/clevergo/views/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer returns a views.Tracer that creates spans with the given
+// OpenTelemetry tracer.
+func NewTracer(t trace.Tracer) views.Tracer {
+	return &tracer{t}
+}
+
+func (t *tracer) Start(ctx context.Context, name string) (context.Context, views.Span) {
+	ctx, s := t.tracer.Start(ctx, name)
+	return ctx, &span{s}
+}
+
+type span struct {
+	span trace.Span
+}
+
+func (s *span) SetAttribute(key string, value interface{}) {
+	var kv attribute.KeyValue
+	switch v := value.(type) {
+	case string:
+		kv = attribute.String(key, v)
+	case bool:
+		kv = attribute.Bool(key, v)
+	case int:
+		kv = attribute.Int(key, v)
+	case int64:
+		kv = attribute.Int64(key, v)
+	case float64:
+		kv = attribute.Float64(key, v)
+	default:
+		kv = attribute.String(key, fmt.Sprint(v))
+	}
+	s.span.SetAttributes(kv)
+}
+
+func (s *span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *span) End() {
+	s.span.End()
+}
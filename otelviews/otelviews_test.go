@@ -0,0 +1,58 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package otelviews
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/clevergo/views/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	m := views.New(http.Dir("../example/views"), views.Tracing(NewTracer(provider.Tracer("views"))))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "handler")
+	if err := m.RenderPartialContext(ctx, bytes.NewBuffer(nil), "user/login", nil); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	if err := m.RenderPartialContext(ctx, bytes.NewBuffer(nil), "nonexistent", nil); err == nil {
+		t.Fatal("expected an error about view file not found, got nil")
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 5 {
+		t.Fatalf("expected 5 spans, got %d", len(spans))
+	}
+	compile, execute := spans[0], spans[1]
+	if compile.Name() != "views.compile" || execute.Name() != "views.execute" {
+		t.Fatalf("unexpected spans %s and %s", compile.Name(), execute.Name())
+	}
+	if compile.Parent().SpanID() != execute.SpanContext().SpanID() {
+		t.Error("expected compile span to be a child of execute span")
+	}
+	if execute.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("expected execute span to be a child of handler span")
+	}
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range execute.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	if attrs["views.view"].AsString() != "user/login" || attrs["views.cache_hit"].AsBool() || attrs["views.output_size"].AsInt64() == 0 {
+		t.Errorf("unexpected attributes %v", execute.Attributes())
+	}
+	if spans[3].Status().Code != codes.Error {
+		t.Errorf("expected error status, got %v", spans[3].Status())
+	}
+}
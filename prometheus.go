@@ -0,0 +1,206 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets is the default buckets of duration histograms, in seconds.
+var DefaultBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (h *histogram) observe(buckets []float64, v float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(buckets))
+	}
+	for i, bound := range buckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+type viewMetrics struct {
+	renders         uint64
+	renderErrors    uint64
+	renderBytes     uint64
+	renderDuration  histogram
+	compiles        uint64
+	compileErrors   uint64
+	compileDuration histogram
+	cacheHits       uint64
+	cacheMisses     uint64
+}
+
+type viewKey struct {
+	layout string
+	view   string
+}
+
+// PrometheusCollector is a collector that exposes the metrics in Prometheus
+// text exposition format.
+//
+//	collector := views.NewPrometheusCollector("")
+//	manager := views.New(fs, views.Metrics(collector))
+//	http.Handle("/metrics", collector)
+type PrometheusCollector struct {
+	namespace string
+	buckets   []float64
+	mutex     sync.Mutex
+	metrics   map[viewKey]*viewMetrics
+}
+
+// NewPrometheusCollector returns a Prometheus collector with the given
+// namespace, defaults to "views".
+func NewPrometheusCollector(namespace string) *PrometheusCollector {
+	if namespace == "" {
+		namespace = "views"
+	}
+	return &PrometheusCollector{
+		namespace: namespace,
+		buckets:   DefaultBuckets,
+		metrics:   make(map[viewKey]*viewMetrics),
+	}
+}
+
+func (c *PrometheusCollector) get(layout, view string) *viewMetrics {
+	key := viewKey{layout, view}
+	m, ok := c.metrics[key]
+	if !ok {
+		m = &viewMetrics{}
+		c.metrics[key] = m
+	}
+	return m
+}
+
+// ObserveRender implements Collector.
+func (c *PrometheusCollector) ObserveRender(layout, view string, duration time.Duration, written int, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	m := c.get(layout, view)
+	m.renders++
+	if err != nil {
+		m.renderErrors++
+	}
+	m.renderBytes += uint64(written)
+	m.renderDuration.observe(c.buckets, duration.Seconds())
+}
+
+// ObserveCompile implements Collector.
+func (c *PrometheusCollector) ObserveCompile(layout, view string, duration time.Duration, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	m := c.get(layout, view)
+	m.compiles++
+	if err != nil {
+		m.compileErrors++
+	}
+	m.compileDuration.observe(c.buckets, duration.Seconds())
+}
+
+// ObserveCache implements Collector.
+func (c *PrometheusCollector) ObserveCache(layout, view string, hit bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	m := c.get(layout, view)
+	if hit {
+		m.cacheHits++
+	} else {
+		m.cacheMisses++
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (c *PrometheusCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(c.Expose())
+}
+
+// Expose returns the metrics in Prometheus text exposition format.
+func (c *PrometheusCollector) Expose() []byte {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keys := make([]viewKey, 0, len(c.metrics))
+	for key := range c.metrics {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].layout != keys[j].layout {
+			return keys[i].layout < keys[j].layout
+		}
+		return keys[i].view < keys[j].view
+	})
+
+	buf := bytes.NewBuffer(nil)
+	counters := []struct {
+		name  string
+		help  string
+		value func(*viewMetrics) uint64
+	}{
+		{"render_total", "Total number of renders.", func(m *viewMetrics) uint64 { return m.renders }},
+		{"render_errors_total", "Total number of failed renders.", func(m *viewMetrics) uint64 { return m.renderErrors }},
+		{"render_bytes_total", "Total number of bytes written by renders.", func(m *viewMetrics) uint64 { return m.renderBytes }},
+		{"compile_total", "Total number of template compilations.", func(m *viewMetrics) uint64 { return m.compiles }},
+		{"compile_errors_total", "Total number of failed template compilations.", func(m *viewMetrics) uint64 { return m.compileErrors }},
+		{"cache_hits_total", "Total number of template cache hits.", func(m *viewMetrics) uint64 { return m.cacheHits }},
+		{"cache_misses_total", "Total number of template cache misses.", func(m *viewMetrics) uint64 { return m.cacheMisses }},
+	}
+	for _, counter := range counters {
+		name := c.namespace + "_" + counter.name
+		fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s counter\n", name, counter.help, name)
+		for _, key := range keys {
+			fmt.Fprintf(buf, "%s{%s} %d\n", name, labels(key), counter.value(c.metrics[key]))
+		}
+	}
+
+	histograms := []struct {
+		name  string
+		help  string
+		value func(*viewMetrics) *histogram
+	}{
+		{"render_duration_seconds", "Duration of renders in seconds.", func(m *viewMetrics) *histogram { return &m.renderDuration }},
+		{"compile_duration_seconds", "Duration of template compilations in seconds.", func(m *viewMetrics) *histogram { return &m.compileDuration }},
+	}
+	for _, h := range histograms {
+		name := c.namespace + "_" + h.name
+		fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s histogram\n", name, h.help, name)
+		for _, key := range keys {
+			v := h.value(c.metrics[key])
+			if v.count == 0 {
+				continue
+			}
+			for i, bound := range c.buckets {
+				fmt.Fprintf(buf, "%s_bucket{%s,le=%q} %d\n", name, labels(key), strconv.FormatFloat(bound, 'g', -1, 64), v.counts[i])
+			}
+			fmt.Fprintf(buf, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels(key), v.count)
+			fmt.Fprintf(buf, "%s_sum{%s} %s\n", name, labels(key), strconv.FormatFloat(v.sum, 'g', -1, 64))
+			fmt.Fprintf(buf, "%s_count{%s} %d\n", name, labels(key), v.count)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labels(key viewKey) string {
+	return `layout="` + labelValueReplacer.Replace(key.layout) + `",view="` + labelValueReplacer.Replace(key.view) + `"`
+}
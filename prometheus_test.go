@@ -0,0 +1,58 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusCollector(t *testing.T) {
+	c := NewPrometheusCollector("")
+	c.ObserveCache("main", "site/index", false)
+	c.ObserveCompile("main", "site/index", 2*time.Millisecond, nil)
+	c.ObserveRender("main", "site/index", 3*time.Millisecond, 100, nil)
+	c.ObserveCache("main", "site/index", true)
+	c.ObserveRender("main", "site/index", time.Second, 0, errors.New("failed"))
+	c.ObserveCache("", `a"b`, false)
+
+	w := httptest.NewRecorder()
+	c.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", contentType)
+	}
+	body := w.Body.String()
+	lines := []string{
+		"# TYPE views_render_total counter",
+		`views_render_total{layout="main",view="site/index"} 2`,
+		`views_render_errors_total{layout="main",view="site/index"} 1`,
+		`views_render_bytes_total{layout="main",view="site/index"} 100`,
+		`views_compile_total{layout="main",view="site/index"} 1`,
+		`views_compile_errors_total{layout="main",view="site/index"} 0`,
+		`views_cache_hits_total{layout="main",view="site/index"} 1`,
+		`views_cache_misses_total{layout="main",view="site/index"} 1`,
+		`views_cache_misses_total{layout="",view="a\"b"} 1`,
+		"# TYPE views_render_duration_seconds histogram",
+		`views_render_duration_seconds_bucket{layout="main",view="site/index",le="0.0025"} 0`,
+		`views_render_duration_seconds_bucket{layout="main",view="site/index",le="0.005"} 1`,
+		`views_render_duration_seconds_bucket{layout="main",view="site/index",le="1"} 2`,
+		`views_render_duration_seconds_bucket{layout="main",view="site/index",le="+Inf"} 2`,
+		`views_render_duration_seconds_sum{layout="main",view="site/index"} 1.003`,
+		`views_render_duration_seconds_count{layout="main",view="site/index"} 2`,
+		`views_compile_duration_seconds_count{layout="main",view="site/index"} 1`,
+	}
+	for _, line := range lines {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected line %q in:\n%s", line, body)
+		}
+	}
+	if strings.Contains(body, `views_compile_duration_seconds_count{layout="",view="a\"b"}`) {
+		t.Error("expected empty histograms to be omitted")
+	}
+}
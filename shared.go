@@ -0,0 +1,28 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+// Share shares a value with all templates, it is safe to be called
+// concurrently, so that the value can be updated at runtime. The shared
+// values can be accessed in any views, layouts and partials via the global
+// function:
+//
+//	{{ global "appName" }}
+func (m *Manager) Share(key string, value interface{}) {
+	m.sharedMutex.Lock()
+	defer m.sharedMutex.Unlock()
+	if m.shared == nil {
+		m.shared = make(map[string]interface{})
+	}
+	m.shared[key] = value
+}
+
+// Shared returns the shared value of the given key, nil is returned if
+// the key does not exist.
+func (m *Manager) Shared(key string) interface{} {
+	m.sharedMutex.RLock()
+	defer m.sharedMutex.RUnlock()
+	return m.shared[key]
+}
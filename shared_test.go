@@ -0,0 +1,44 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestManagerShare(t *testing.T) {
+	m := New(testViewsFileSystem, Share(map[string]interface{}{"appName": "foo"}))
+	if v := m.Shared("appName"); v != "foo" {
+		t.Errorf("expected shared value %q, got %v", "foo", v)
+	}
+	if v := m.Shared("nonexistent"); v != nil {
+		t.Errorf("expected nil, got %v", v)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m.Share("version", i)
+			if err := m.RenderPartial(bytes.NewBuffer(nil), "shared", nil); err != nil {
+				t.Errorf("failed to render: %s", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	m.Share("version", "v1")
+	w := bytes.NewBuffer(nil)
+	if err := m.RenderPartial(w, "shared", nil); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	expected := "foo v1"
+	if actual := string(bytes.TrimSpace(w.Bytes())); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import "context"
+
+// Tracer creates spans, see package github.com/clevergo/views/otelviews for
+// the OpenTelemetry adapter.
+type Tracer interface {
+	// Start creates a span, and returns a context that contains the span, so
+	// that the spans created with the context are children of the span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span of trace.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Tracing sets the tracer, the spans "views.compile" and "views.execute" are
+// created while rendering with context, and annotated with layout, view,
+// cache hit and output size.
+func Tracing(tracer Tracer) Option {
+	return func(m *Manager) {
+		m.tracer = tracer
+	}
+}
+
+// span is a nil-safe wrapper of Span.
+type span struct {
+	Span
+}
+
+func (s span) SetAttribute(key string, value interface{}) {
+	if s.Span != nil {
+		s.Span.SetAttribute(key, value)
+	}
+}
+
+func (s span) end(err error) {
+	if s.Span == nil {
+		return
+	}
+	if err != nil {
+		s.RecordError(err)
+	}
+	s.End()
+}
+
+func (m *Manager) startSpan(ctx context.Context, name, layout, view string) (context.Context, span) {
+	if m.tracer == nil {
+		return ctx, span{}
+	}
+	ctx, s := m.tracer.Start(ctx, name)
+	s.SetAttribute("views.layout", layout)
+	s.SetAttribute("views.view", view)
+	return ctx, span{s}
+}
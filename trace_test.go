@@ -0,0 +1,93 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+type fakeSpan struct {
+	name       string
+	parent     *fakeSpan
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *fakeSpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+
+func (s *fakeSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *fakeSpan) End() {
+	s.ended = true
+}
+
+type fakeSpanKey struct{}
+
+type fakeTracer struct {
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(fakeSpanKey{}).(*fakeSpan)
+	s := &fakeSpan{name: name, parent: parent, attributes: map[string]interface{}{}}
+	t.spans = append(t.spans, s)
+	return context.WithValue(ctx, fakeSpanKey{}, s), s
+}
+
+func TestTracing(t *testing.T) {
+	tracer := &fakeTracer{}
+	m := New(testViewsFileSystem, Tracing(tracer))
+	m.Share("appName", "foo")
+	for i := 0; i < 2; i++ {
+		if err := m.RenderPartial(bytes.NewBuffer(nil), "shared", nil); err != nil {
+			t.Fatalf("failed to render: %s", err)
+		}
+	}
+	if len(tracer.spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(tracer.spans))
+	}
+	execute, compile, cached := tracer.spans[0], tracer.spans[1], tracer.spans[2]
+	if execute.name != "views.execute" || compile.name != "views.compile" || cached.name != "views.execute" {
+		t.Errorf("unexpected spans %s, %s and %s", execute.name, compile.name, cached.name)
+	}
+	if compile.parent != execute {
+		t.Error("expected compile span to be a child of execute span")
+	}
+	tests := []struct {
+		span       *fakeSpan
+		attributes map[string]interface{}
+	}{
+		{execute, map[string]interface{}{"views.layout": "", "views.view": "shared", "views.cache_hit": false, "views.output_size": 5}},
+		{compile, map[string]interface{}{"views.view": "shared", "views.files": "/shared.tmpl"}},
+		{cached, map[string]interface{}{"views.cache_hit": true}},
+	}
+	for _, test := range tests {
+		if !test.span.ended {
+			t.Errorf("expected span %s to be ended", test.span.name)
+		}
+		for key, value := range test.attributes {
+			if actual := test.span.attributes[key]; actual != value {
+				t.Errorf("%s: expected attribute %s %v, got %v", test.span.name, key, value, actual)
+			}
+		}
+	}
+
+	tracer.spans = nil
+	if err := m.RenderPartial(bytes.NewBuffer(nil), "nonexistent", nil); err == nil {
+		t.Fatal("expected an error about view file not found, got nil")
+	}
+	for _, s := range tracer.spans {
+		if s.err == nil {
+			t.Errorf("expected span %s to record the error", s.name)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// URLBuilder builds the URL of the named route with the arguments, such as
+// the names and values of route parameters.
+type URLBuilder interface {
+	URL(name string, args ...string) (string, error)
+}
+
+// URLBuilderFunc is an adapter to allow the use of ordinary functions as
+// URLBuilder.
+type URLBuilderFunc func(name string, args ...string) (string, error)
+
+// URL implements URLBuilder.
+func (f URLBuilderFunc) URL(name string, args ...string) (string, error) {
+	return f(name, args...)
+}
+
+// Router is a router that builds URL of named routes, such as the router of
+// clevergo.
+type Router interface {
+	URL(name string, args ...string) (*url.URL, error)
+}
+
+// RouterURLBuilder returns a URL builder of the router.
+//
+//	router := clevergo.NewRouter()
+//	router.Get("/users/:name", user, clevergo.RouteName("user"))
+//	manager := views.New(fs, views.Routing(views.RouterURLBuilder(router)))
+func RouterURLBuilder(router Router) URLBuilder {
+	return URLBuilderFunc(func(name string, args ...string) (string, error) {
+		u, err := router.URL(name, args...)
+		if err != nil {
+			return "", err
+		}
+		return u.String(), nil
+	})
+}
+
+// Routing is an option that registers the url function with the URL builder,
+// the arguments are converted to strings.
+//
+//	<a href="{{ url "login" }}">Login</a>
+//	<a href="{{ url "user" "name" .user.Name }}">Profile</a>
+func Routing(builder URLBuilder) Option {
+	return func(m *Manager) {
+		m.AddFunc("url", func(name string, args ...interface{}) (string, error) {
+			s := make([]string, len(args))
+			for i, arg := range args {
+				s[i] = fmt.Sprint(arg)
+			}
+			return builder.URL(name, s...)
+		})
+	}
+}
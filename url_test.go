@@ -0,0 +1,59 @@
+// Copyright 2020 CleverGo. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package views
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type testRouter map[string]string
+
+func (r testRouter) URL(name string, args ...string) (*url.URL, error) {
+	path, ok := r[name]
+	if !ok {
+		return nil, fmt.Errorf("no such route %q", name)
+	}
+	for i := 0; i+1 < len(args); i += 2 {
+		path = strings.Replace(path, ":"+args[i], url.PathEscape(args[i+1]), -1)
+	}
+	return url.Parse(path)
+}
+
+func TestRouting(t *testing.T) {
+	router := testRouter{"login": "/login", "user": "/users/:name/:id"}
+	m := New(testViewsFileSystem, Routing(RouterURLBuilder(router)))
+	w := bytes.NewBuffer(nil)
+	if err := m.RenderPartial(w, "url", map[string]interface{}{"name": "foo bar", "id": 1}); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	expected := `<a href="/login">Login</a> <a href="/users/foo%20bar/1">Profile</a>` + "\n"
+	if actual := w.String(); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	delete(router, "login")
+	if err := m.RenderPartial(w, "url", nil); err == nil || !strings.Contains(err.Error(), `no such route "login"`) {
+		t.Errorf("expected an error about undefined route, got %v", err)
+	}
+}
+
+func TestURLBuilderFunc(t *testing.T) {
+	builder := URLBuilderFunc(func(name string, args ...string) (string, error) {
+		return "/" + name + "/" + strings.Join(args, "/"), nil
+	})
+	m := New(testViewsFileSystem, Routing(builder))
+	w := bytes.NewBuffer(nil)
+	if err := m.RenderPartial(w, "url", map[string]interface{}{"name": "foo", "id": 1}); err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+	expected := `<a href="/login/">Login</a> <a href="/user/name/foo/id/1">Profile</a>` + "\n"
+	if actual := w.String(); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}